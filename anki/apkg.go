@@ -0,0 +1,284 @@
+// Package anki writes Anki deck packages (.apkg), a zip archive holding
+// the SQLite collection and the media index.
+package anki
+
+import (
+	"archive/zip"
+	"crypto/sha1"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	_ "github.com/mattn/go-sqlite3"
+)
+
+// Model is an Anki note type with a single card template. Front and Back
+// are the question and answer templates, e.g. "{{Word}}".
+type Model struct {
+	ID     int64
+	Name   string
+	Fields []string
+	Front  string
+	Back   string
+	CSS    string
+}
+
+// Note is a note of a deck. Its fields are HTML.
+type Note struct {
+	Model  *Model
+	GUID   string
+	Fields []string
+	Tags   []string
+}
+
+// Deck is a named list of notes, each of which becomes a single card.
+type Deck struct {
+	Name  string
+	Notes []*Note
+
+	// the GUIDs of the notes added so far
+	guids map[string]bool
+}
+
+var collectionSchema = []string{
+	"CREATE TABLE col (id INTEGER PRIMARY KEY, crt INTEGER NOT NULL, mod INTEGER NOT NULL, scm INTEGER NOT NULL, " +
+		"ver INTEGER NOT NULL, dty INTEGER NOT NULL, usn INTEGER NOT NULL, ls INTEGER NOT NULL, conf TEXT NOT NULL, " +
+		"models TEXT NOT NULL, decks TEXT NOT NULL, dconf TEXT NOT NULL, tags TEXT NOT NULL)",
+	"CREATE TABLE notes (id INTEGER PRIMARY KEY, guid TEXT NOT NULL, mid INTEGER NOT NULL, mod INTEGER NOT NULL, " +
+		"usn INTEGER NOT NULL, tags TEXT NOT NULL, flds TEXT NOT NULL, sfld INTEGER NOT NULL, csum INTEGER NOT NULL, " +
+		"flags INTEGER NOT NULL, data TEXT NOT NULL)",
+	"CREATE TABLE cards (id INTEGER PRIMARY KEY, nid INTEGER NOT NULL, did INTEGER NOT NULL, ord INTEGER NOT NULL, " +
+		"mod INTEGER NOT NULL, usn INTEGER NOT NULL, type INTEGER NOT NULL, queue INTEGER NOT NULL, due INTEGER NOT NULL, " +
+		"ivl INTEGER NOT NULL, factor INTEGER NOT NULL, reps INTEGER NOT NULL, lapses INTEGER NOT NULL, left INTEGER NOT NULL, " +
+		"odue INTEGER NOT NULL, odid INTEGER NOT NULL, flags INTEGER NOT NULL, data TEXT NOT NULL)",
+	"CREATE TABLE revlog (id INTEGER PRIMARY KEY, cid INTEGER NOT NULL, usn INTEGER NOT NULL, ease INTEGER NOT NULL, " +
+		"ivl INTEGER NOT NULL, lastIvl INTEGER NOT NULL, factor INTEGER NOT NULL, time INTEGER NOT NULL, type INTEGER NOT NULL)",
+	"CREATE TABLE graves (usn INTEGER NOT NULL, oid INTEGER NOT NULL, type INTEGER NOT NULL)",
+	"CREATE INDEX ix_notes_usn ON notes (usn)",
+	"CREATE INDEX ix_cards_usn ON cards (usn)",
+	"CREATE INDEX ix_revlog_usn ON revlog (usn)",
+	"CREATE INDEX ix_cards_nid ON cards (nid)",
+	"CREATE INDEX ix_cards_sched ON cards (did, queue, due)",
+	"CREATE INDEX ix_revlog_cid ON revlog (cid)",
+	"CREATE INDEX ix_notes_csum ON notes (csum)",
+}
+
+// the options of the default deck, which every collection needs
+const defaultDeckConf = `{"1": {"id": 1, "name": "Default", "mod": 0, "usn": 0, "maxTaken": 60, "autoplay": true,
+"timer": 0, "replayq": true, "dyn": false,
+"new": {"delays": [1, 10], "ints": [1, 4, 7], "initialFactor": 2500, "separate": true, "order": 1, "perDay": 20, "bury": false},
+"lapse": {"delays": [10], "mult": 0, "minInt": 1, "leechFails": 8, "leechAction": 0},
+"rev": {"perDay": 200, "ease4": 1.3, "fuzz": 0.05, "minSpace": 1, "ivlFct": 1, "maxIvl": 36500, "bury": false, "hardFactor": 1.2}}}`
+
+var htmlTag = regexp.MustCompile(`<[^>]*>`)
+
+// NewDeck returns an empty deck.
+func NewDeck(name string) *Deck {
+	return &Deck{Name: name, guids: make(map[string]bool)}
+}
+
+// Add adds a note of model m with the given plain text fields to the deck.
+// key identifies the note across exports: notes with the same key get the
+// same GUID, so importing a newer export updates them instead of adding
+// duplicates. A note whose key has already been added is left out.
+func (d *Deck) Add(m *Model, key string, fields []string, tags ...string) {
+	guid := GUID(key)
+	if d.guids[guid] {
+		return
+	}
+	d.guids[guid] = true
+
+	escaped := make([]string, len(fields))
+	for i, f := range fields {
+		escaped[i] = html.EscapeString(f)
+	}
+
+	d.Notes = append(d.Notes, &Note{Model: m, GUID: guid, Fields: escaped, Tags: tags})
+}
+
+// GUID returns the note GUID belonging to key.
+func GUID(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return base64.RawURLEncoding.EncodeToString(sum[:9])
+}
+
+// WriteFile writes the deck as Anki package to path.
+func (d *Deck) WriteFile(path string) error {
+	tmp, err := os.CreateTemp("", "msyu-*.anki2")
+	if err != nil {
+		return err
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	if err := d.writeCollection(tmp.Name()); err != nil {
+		return err
+	}
+
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+
+	zw := zip.NewWriter(out)
+	err = addFile(zw, "collection.anki2", tmp.Name())
+	if err == nil {
+		var w io.Writer
+		if w, err = zw.Create("media"); err == nil {
+			_, err = io.WriteString(w, "{}")
+		}
+	}
+	if err == nil {
+		err = zw.Close()
+	}
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func addFile(zw *zip.Writer, name string, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(w, f)
+	return err
+}
+
+func (d *Deck) writeCollection(path string) error {
+	db, err := sql.Open("sqlite3", path)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	for _, stmt := range collectionSchema {
+		if _, err := db.Exec(stmt); err != nil {
+			return err
+		}
+	}
+
+	now := time.Now()
+	deckID := id("deck/" + d.Name)
+
+	models := make(map[string]interface{})
+	for _, n := range d.Notes {
+		models[strconv.FormatInt(n.Model.ID, 10)] = n.Model.json(deckID, now)
+	}
+
+	decks := map[string]interface{}{
+		"1":                           deckJSON(1, "Default", now),
+		strconv.FormatInt(deckID, 10): deckJSON(deckID, d.Name, now),
+	}
+
+	conf := map[string]interface{}{
+		"activeDecks": []int64{deckID}, "curDeck": deckID, "newSpread": 0, "collapseTime": 1200,
+		"timeLim": 0, "estTimes": true, "dueCounts": true, "curModel": nil, "nextPos": len(d.Notes) + 1,
+		"sortType": "noteFld", "sortBackwards": false, "addToCur": true,
+	}
+
+	crt := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	_, err = db.Exec("INSERT INTO col VALUES (1, ?, ?, ?, 11, 0, 0, 0, ?, ?, ?, ?, '{}')",
+		crt.Unix(), now.UnixNano()/1e6, now.UnixNano()/1e6, marshal(conf), marshal(models), marshal(decks), defaultDeckConf)
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+
+	for i, n := range d.Notes {
+		nid := id("note/" + n.GUID)
+		sort := htmlTag.ReplaceAllString(n.Fields[0], "")
+		sum := sha1.Sum([]byte(sort))
+
+		tags := ""
+		if len(n.Tags) > 0 {
+			tags = " " + strings.Join(n.Tags, " ") + " "
+		}
+
+		_, err := tx.Exec("INSERT INTO notes VALUES (?, ?, ?, ?, -1, ?, ?, ?, ?, 0, '')",
+			nid, n.GUID, n.Model.ID, now.Unix(), tags, strings.Join(n.Fields, "\x1f"), sort,
+			binary.BigEndian.Uint32(sum[:4]))
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+
+		_, err = tx.Exec("INSERT INTO cards VALUES (?, ?, ?, 0, ?, -1, 0, 0, ?, 0, 0, 0, 0, 0, 0, 0, 0, '')",
+			id("card/"+n.GUID), nid, deckID, now.Unix(), i+1)
+		if err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (m *Model) json(deckID int64, now time.Time) map[string]interface{} {
+	var fields []map[string]interface{}
+	for i, name := range m.Fields {
+		fields = append(fields, map[string]interface{}{
+			"name": name, "ord": i, "sticky": false, "rtl": false, "font": "Arial", "size": 20, "media": []string{},
+		})
+	}
+
+	return map[string]interface{}{
+		"id": m.ID, "name": m.Name, "type": 0, "mod": now.Unix(), "usn": -1, "sortf": 0, "did": deckID,
+		"tmpls": []map[string]interface{}{{
+			"name": "Card 1", "ord": 0, "qfmt": m.Front, "afmt": m.Back, "did": nil, "bqfmt": "", "bafmt": "",
+		}},
+		"flds": fields,
+		"css":  m.CSS,
+		"latexPre": "\\documentclass[12pt]{article}\n\\special{papersize=3in,5in}\n\\usepackage[utf8]{inputenc}\n" +
+			"\\usepackage{amssymb,amsmath}\n\\pagestyle{empty}\n\\setlength{\\parindent}{0in}\n\\begin{document}\n",
+		"latexPost": "\\end{document}",
+		"tags":      []string{},
+		"vers":      []string{},
+		// the card is generated if the first field is filled in
+		"req": []interface{}{[]interface{}{0, "any", []int{0}}},
+	}
+}
+
+func deckJSON(id int64, name string, now time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"id": id, "name": name, "mod": now.Unix(), "usn": -1, "desc": "", "dyn": 0, "conf": 1,
+		"collapsed": false, "browserCollapsed": false, "extendNew": 0, "extendRev": 50,
+		"lrnToday": []int{0, 0}, "revToday": []int{0, 0}, "newToday": []int{0, 0}, "timeToday": []int{0, 0},
+	}
+}
+
+// id returns a stable positive id for key that fits into the 53 bits
+// JavaScript numbers can hold.
+func id(key string) int64 {
+	sum := sha256.Sum256([]byte(key))
+	return int64(binary.BigEndian.Uint64(sum[:8]) >> 11)
+}
+
+func marshal(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(fmt.Sprintf("anki: %v", err))
+	}
+	return string(b)
+}
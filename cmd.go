@@ -1,12 +1,22 @@
 package main
 
 import (
-	"crypto/rand"
+	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"net/http"
 	"os"
 	"strconv"
 	"strings"
+	"unicode"
+
+	"github.com/tsurai/msyu/anki"
+	"github.com/tsurai/msyu/conjugate"
+	"github.com/tsurai/msyu/dict"
+	"github.com/tsurai/msyu/romaji"
+	"github.com/tsurai/msyu/wanikani"
 )
 
 var commands = []command{
@@ -18,18 +28,191 @@ var commands = []command{
 	},
 	{
 		Run:       conj,
-		UsageLine: "conj [word]",
+		UsageLine: "conj [-romaji] [-kunrei] [-format f] [-chain steps] [-explain] [word]",
 		Short:     "prints conjugation table",
-		Long:      `Prints the conjugation table of a given word. Uses a random verb instead if no word is supplied.`,
+		Long: `Prints the conjugation table of a given verb or adjective. Uses a random word instead if no word is supplied.
+The word may be given in kana, kanji, romaji or english.
+
+    -romaji       add the Hepburn romanization of each form
+    -kunrei       add the Kunrei romanization of each form
+    -format f     output format, one of text, markdown, csv, json or html
+    -chain steps  print a single stacked form instead of the table. steps is a
+                  comma separated list of the derivations causative, passive,
+                  potential, desiderative, progressive and shimau, applied in
+                  the given order, optionally followed by one of the forms
+                  present, past, te, conditional, provisional, volitional,
+                  conjectural, alternative, imperative, adverbial, zu or zuni
+                  and the modifiers negative and polite,
+                  e.g. causative,passive,past,negative
+    -explain      show how each form is derived from the word, e.g.
+                  書く → godan く → 音便 い → 書い + た → 書いた`,
+	},
+	{
+		Run:       search,
+		UsageLine: "search [-json] [-limit n] [-offset n] [-pos tags] [-examples n] [-common] [-jlpt level] [-exclude-archaic] [term]",
+		Short:     "searches the dictionary",
+		Long: `Prints every dictionary entry matching the given japanese or english term
+without asking for a selection.
+
+    -json              print the entries as a JSON array
+    -limit n           print at most n entries
+    -offset n          skip the first n entries
+    -pos tags          comma separated list of JMdict part of speech tags,
+                       e.g. v1,v5. Only entries with a sense tagged with one
+                       of them are printed. A tag also matches every tag it
+                       is a prefix of.
+    -examples n        print up to n example sentences for every entry
+    -common            only words JMdict marks as common, i.e. frequent in
+                       newspapers or listed in common word lists
+    -jlpt level        only words of a JLPT level like N4 or range of levels
+                       like N5-N3. The levels have to be imported first.
+    -exclude-archaic   leave out archaic, obsolete and obscure words and
+                       rare kanji spellings`,
+	},
+	{
+		Run:       examples,
+		UsageLine: "examples [-n n] [word]",
+		Short:     "prints example sentences",
+		Long: `Prints up to n example sentences using the given word together with their
+english translation, 10 by default. Requires the Tatoeba example sentences
+to be imported.`,
+	},
+	{
+		Run:       deinflect,
+		UsageLine: "deinflect [text]",
+		Short:     "finds the dictionary form of a conjugated word",
+		Long: `Traces a conjugated verb or adjective like 食べさせられなかった back to its
+dictionary form and prints every matching dictionary entry together with
+the inflections that were applied, innermost first.`,
+	},
+	{
+		Run:       kanji,
+		UsageLine: "kanji [-words n] [text]",
+		Short:     "prints information about kanji",
+		Long: `Prints the readings, meanings, stroke count, school grade, JLPT level and
+frequency rank of every kanji in the given text together with the words
+written with it. Requires the KANJIDIC2 file to be imported.
+
+    -words n    print at most n words per kanji, 0 for none`,
 	},
 	{
 		Run:       test,
-		UsageLine: `test [name] [n]`,
+		UsageLine: `test [name] [-source s] [-forms names] [-polarity p] [-politeness p] [-class tags] [-weights w] [-common] [-jlpt level] [-exclude-archaic] [-choices n] [n]`,
 		Short:     "starts an interactive test with n items asked",
 		Long: `Starts a new interactive test with n items asked.
 
     Available tests:
-      conj`,
+      conj
+
+    -source s       where the words are taken from, either dict for the
+                    whole dictionary or wanikani for the vocabulary unlocked
+                    on WaniKani. The latter has to be synced first.
+    -forms names    comma separated list of the forms asked for, e.g.
+                    past,te,causative. Every form by default.
+    -polarity p     positive, negative or both, the default
+    -politeness p   plain, polite or both, the default
+    -class tags     comma separated list of JMdict part of speech tags, e.g.
+                    v5,v1. Only words of one of these classes are asked.
+                    A tag also matches every tag it is a prefix of.
+    -weights w      how often forms are asked relative to each other, e.g.
+                    past=3,te=2. Forms not listed have a weight of 1, a
+                    weight of 0 leaves a form out.
+    -common         only words JMdict marks as common, i.e. frequent in
+                    newspapers or listed in common word lists
+    -jlpt level     only words of a JLPT level like N4 or range of levels
+                    like N5-N3. The levels have to be imported first.
+    -exclude-archaic
+                    leave out archaic, obsolete and obscure words and
+                    rare kanji spellings
+    -choices n      let the answer be chosen from n options instead of
+                    typed, the wrong ones being likely mistakes like a
+                    godan verb conjugated as ichidan verb or a wrong 音便`,
+	},
+	{
+		Run:       review,
+		UsageLine: "review [n]",
+		Short:     "reviews up to n items that are due",
+		Long: `Starts an interactive review of up to n previously tested items whose
+review is due. Answers given in tests and reviews are remembered in
+progress.db and schedule the next review of each item.`,
+	},
+	{
+		Run:       export,
+		UsageLine: "export anki [-o file] [-deck name] [-vocab] [-conj] [-class tags] [-forms names] [-all] [word...]",
+		Short:     "exports words as Anki deck",
+		Long: `Writes an Anki package with a card for each of the given words. The words
+may be given in kana, kanji or romaji and every reading of them is exported.
+Notes keep their identity across exports, so importing a newer package
+updates the cards of an older one instead of adding duplicates.
+
+    -o file       the package to write, msyu.apkg by default
+    -deck name    the name of the deck, msyu by default
+    -vocab        add vocabulary cards showing the word and asking for its
+                  reading and meaning
+    -conj         add a conjugation card for every form, polarity and
+                  politeness of the word. Both kinds are added if neither
+                  -vocab nor -conj is given.
+    -class tags   comma separated list of JMdict part of speech tags, e.g.
+                  v1,v5. Only words of one of these classes are exported.
+                  A tag also matches every tag it is a prefix of.
+    -forms names  comma separated list of the forms to add conjugation
+                  cards for, e.g. "te form,past tense"
+    -all          export every word of the dictionary instead`,
+	},
+	{
+		Run:       serve,
+		UsageLine: "serve [-addr address]",
+		Short:     "serves the dictionary and quizzes over HTTP",
+		Long: `Starts an HTTP server offering a conjugation quiz for the browser at / and
+a JSON API:
+
+    GET  /api/search?q=term        words matching term. limit, offset and
+                                   pos work like the flags of search.
+    GET  /api/conj?id=n&kana=k     conjugation table of the word with the
+                                   entry id n and reading k. explain=1 adds
+                                   the derivations.
+    GET  /api/deinflect?text=form  dictionary forms of a conjugated word
+    POST /api/quiz?n=items         starts a quiz and returns its first
+                                   question
+    GET  /api/quiz/id              current question of the quiz
+    POST /api/quiz/id              answers the current question with
+                                   {"answer": "..."} and returns the
+                                   solution and the next question
+
+    -addr address  the address to listen on, :8080 by default. Use
+                   localhost:8080 to only accept local connections.`,
+	},
+	{
+		Run:       wanikani_cmd,
+		UsageLine: "wanikani sync [-token t] [-url base]",
+		Short:     "syncs the vocabulary unlocked on WaniKani",
+		Long: `Fetches the vocabulary unlocked on WaniKani, looks up the matching
+dictionary entries and remembers them in progress.db, replacing the
+vocabulary of earlier syncs. Tests can then be limited to it with
+-source wanikani.
+
+    -token t    the WaniKani API token, $WANIKANI_TOKEN by default
+    -url base   the base URL of the WaniKani API v2, $WANIKANI_URL or
+                https://api.wanikani.com/v2 by default`,
+	},
+	{
+		Run:       import_dict,
+		UsageLine: "import [-accents | -kanjidic | -examples | -jlpt] [file...]",
+		Short:     "builds the dictionary database",
+		Long: `Creates the dictionary database from the given JMdict XML file. The file
+may be gzip compressed. Any previously imported data is replaced.
+
+    -accents    import the pitch accents of the Kanjium accents.txt file
+                instead. JMdict has to be imported first.
+    -kanjidic   import the KANJIDIC2 XML file instead
+    -examples   import the Tatoeba example sentences instead. The files
+                sentences.csv and jpn_indices.csv are given in this order,
+                optionally followed by links.csv for indices lacking the
+                english translation. JMdict has to be imported first.
+    -jlpt       import the JLPT levels of a vocabulary list instead. Every
+                line holds a word, its reading (empty for kana words) and
+                its level like N5, separated by tabs, or commas if the file
+                ends in .csv. JMdict has to be imported first.`,
 	},
 }
 
@@ -54,26 +237,420 @@ func version(cmd *command, args []string) {
 	fmt.Println("Copyright (C) 2014 Cristian Kubis")
 }
 
-/* TODO: list multiple results and let the user choose */
 func conj(cmd *command, args []string) {
-	var word *word = nil
+	var word *conjugate.Word = nil
+
+	flags := flag.NewFlagSet("conj", flag.ExitOnError)
+	flags.Usage = cmd.Usage
+	hepburn := flags.Bool("romaji", false, "")
+	kunrei := flags.Bool("kunrei", false, "")
+	format := flags.String("format", "text", "")
+	chainSteps := flags.String("chain", "", "")
+	explain := flags.Bool("explain", false, "")
+	flags.Parse(args)
+	args = flags.Args()
+
+	var chain *conjugate.Chain
+	if *chainSteps != "" {
+		var err error
+		if chain, err = conjugate.ParseChain(*chainSteps); err != nil {
+			log.Fatalf("Invalid chain '%s': %s\n", *chainSteps, err)
+		}
+	}
+
+	valid := false
+	for _, f := range formats {
+		valid = valid || f == *format
+	}
+	if !valid {
+		cmd.Usage()
+		os.Exit(2)
+	}
 
 	if len(args) < 1 {
-		word = DB_get_random_verbs(1)[0]
+		words, err := dict.RandomWords(1, dict.CONJUGABLE)
+		if err != nil {
+			log.Fatal("A database error has occured:", err)
+		}
+		if len(words) > 0 {
+			word = words[0]
+		}
+	} else {
+		word = search_word(strings.Join(args, " "), dict.CONJUGABLE)
+	}
+
+	if word == nil {
+		log.Fatalf("Could not find word '%s'\n", strings.Join(args, " "))
+	}
+
+	var roma func(string) string
+	if *kunrei {
+		roma = func(s string) string { return romaji.FromKana(s, romaji.Kunrei) }
+	} else if *hepburn {
+		roma = func(s string) string { return romaji.FromKana(s, romaji.Hepburn) }
+	}
+
+	if chain != nil {
+		printChain(word, chain, roma, *explain)
+		return
+	}
+
+	table, err := newConjTable(word, roma, *explain)
+	if err != nil {
+		log.Fatal("A database error has occured:", err)
+	}
+
+	if *format == "text" {
+		if t := openScreen(); t != nil {
+			defer t.Close()
+			tableScreen(t, table)
+			return
+		}
+	}
+
+	if err := table.Write(os.Stdout, *format); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// printChain prints every word derived by chain from word and the final
+// form. If explain is set the full derivation is printed instead of the
+// derived words.
+func printChain(word *conjugate.Word, chain *conjugate.Chain, roma func(string) string, explain bool) {
+	words, err := chain.Derive(word)
+	if err == nil {
+		var kana, kanji string
+		kana, kanji, err = conjugate.Conjugate(words[len(words)-1], chain.Form, chain.Polarity, chain.Politeness)
+		words = append(words, &conjugate.Word{Kana: kana, Kanji: []string{kanji}})
+	}
+	if err != nil {
+		log.Fatalf("Can't apply chain to '%s': %s\n", word.Kana, err)
+	}
+
+	if explain {
+		trace, _ := chain.Explain(word)
+		fmt.Println(trace)
 	} else {
-		arg := args[0]
+		var steps []string
+		for _, w := range words {
+			if w.Kanji[0] != "" {
+				steps = append(steps, w.Kanji[0])
+			} else {
+				steps = append(steps, w.Kana)
+			}
+		}
+		fmt.Println(strings.Join(steps, " → "))
+	}
+
+	result := words[len(words)-1]
+	fmt.Print(result.Kana)
+	if result.Kanji[0] != "" {
+		fmt.Printf(" (%s)", result.Kanji[0])
+	}
+	if roma != nil {
+		fmt.Printf(" %s", roma(result.Kana))
+	}
+	fmt.Println()
+}
+
+type jsonSense struct {
+	Pos     []string `json:"pos"`
+	Glosses []string `json:"glosses"`
+}
+
+type jsonExample struct {
+	Japanese string `json:"japanese"`
+	English  string `json:"english"`
+}
+
+type jsonWord struct {
+	ID       int           `json:"id"`
+	Kana     string        `json:"kana"`
+	Kanji    []string      `json:"kanji"`
+	Senses   []jsonSense   `json:"senses"`
+	Examples []jsonExample `json:"examples,omitempty"`
+}
+
+func toJsonWord(w *conjugate.Word) jsonWord {
+	jw := jsonWord{ID: w.ID, Kana: w.Kana, Kanji: []string{}, Senses: []jsonSense{}}
+
+	for _, k := range w.Kanji {
+		if k != "" {
+			jw.Kanji = append(jw.Kanji, k)
+		}
+	}
+
+	for _, g := range w.Gloss {
+		s := jsonSense{Pos: []string{}, Glosses: g.Meaning}
+		for _, p := range g.Pos {
+			if p != "" {
+				s.Pos = append(s.Pos, p)
+			}
+		}
+		jw.Senses = append(jw.Senses, s)
+	}
+
+	return jw
+}
 
-		if isJapaneseString(arg) {
-			word = DB_search_word(arg, JAP, VERB)
-		} else if isLatin(arg) {
-			word = DB_search_word(arg, EN, VERB)
+// hasPos reports whether a sense of w is tagged with one of tags or a tag
+// they are a prefix of.
+func hasPos(w *conjugate.Word, tags []string) bool {
+	for _, g := range w.Gloss {
+		for _, p := range g.Pos {
+			for _, t := range tags {
+				if strings.HasPrefix(p, t) {
+					return true
+				}
+			}
 		}
 	}
+	return false
+}
+
+func search(cmd *command, args []string) {
+	flags := flag.NewFlagSet("search", flag.ExitOnError)
+	flags.Usage = cmd.Usage
+	asJson := flags.Bool("json", false, "")
+	limit := flags.Int("limit", 0, "")
+	offset := flags.Int("offset", 0, "")
+	pos := flags.String("pos", "", "")
+	numExamples := flags.Int("examples", 0, "")
+	common := flags.Bool("common", false, "")
+	jlpt := flags.String("jlpt", "", "")
+	excludeArchaic := flags.Bool("exclude-archaic", false, "")
+	flags.Parse(args)
+
+	if flags.NArg() < 1 || *limit < 0 || *offset < 0 || *numExamples < 0 {
+		cmd.Usage()
+		os.Exit(2)
+	}
+
+	levels, err := parseJLPT(*jlpt)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	words, err := lookup_words(strings.Join(flags.Args(), " "), dict.ALL)
+	if err != nil {
+		log.Fatal("A database error has occured:", err)
+	}
 
+	if *common || levels != nil || *excludeArchaic {
+		c := dict.Criteria{Common: *common, JLPT: levels, ExcludeArchaic: *excludeArchaic}
+		if words, err = dict.Filter(words, c); err != nil {
+			filterFailed(err)
+		}
+	}
+
+	if *pos != "" {
+		var matches []*conjugate.Word
+		tags := strings.Split(*pos, ",")
+
+		for _, w := range words {
+			if hasPos(w, tags) {
+				matches = append(matches, w)
+			}
+		}
+		words = matches
+	}
+
+	if *offset < len(words) {
+		words = words[*offset:]
+	} else {
+		words = nil
+	}
+	if *limit > 0 && *limit < len(words) {
+		words = words[:*limit]
+	}
+
+	if *asJson {
+		out := []jsonWord{}
+		for _, w := range words {
+			jw := toJsonWord(w)
+			if *numExamples > 0 {
+				for _, e := range wordExamples(w, *numExamples) {
+					jw.Examples = append(jw.Examples, jsonExample{e.Japanese, e.English})
+				}
+			}
+			out = append(out, jw)
+		}
+
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(out); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	for i, w := range words {
+		fmt.Printf("%d: ", *offset+i+1)
+		printWord(w)
+		if *numExamples > 0 {
+			printExamples(wordExamples(w, *numExamples))
+		}
+	}
+}
+
+func examples(cmd *command, args []string) {
+	flags := flag.NewFlagSet("examples", flag.ExitOnError)
+	flags.Usage = cmd.Usage
+	n := flags.Int("n", 10, "")
+	flags.Parse(args)
+
+	if flags.NArg() < 1 || *n <= 0 {
+		cmd.Usage()
+		os.Exit(2)
+	}
+
+	word := search_word(strings.Join(flags.Args(), " "), dict.ALL)
 	if word == nil {
-		log.Fatal("Could not find word '%s'\n", args[0])
+		log.Fatalf("Could not find word '%s'\n", strings.Join(flags.Args(), " "))
+	}
+
+	printWord(word)
+	examples := wordExamples(word, *n)
+	if len(examples) == 0 {
+		fmt.Println("\nNo examples found")
+		return
+	}
+	printExamples(examples)
+}
+
+// wordExamples returns up to n example sentences using w.
+func wordExamples(w *conjugate.Word, n int) []*dict.Example {
+	examples, err := dict.Examples(w, n)
+	if err != nil {
+		log.Fatal("A database error has occured:", err)
+	}
+	return examples
+}
+
+func printExamples(examples []*dict.Example) {
+	for _, e := range examples {
+		fmt.Printf("    > %s\n      %s\n", e.Japanese, e.English)
+	}
+}
+
+func kanji(cmd *command, args []string) {
+	flags := flag.NewFlagSet("kanji", flag.ExitOnError)
+	flags.Usage = cmd.Usage
+	limit := flags.Int("words", 10, "")
+	flags.Parse(args)
+
+	if flags.NArg() < 1 || *limit < 0 {
+		cmd.Usage()
+		os.Exit(2)
+	}
+
+	found := false
+	for _, r := range strings.Join(flags.Args(), "") {
+		if !unicode.Is(unicode.Han, r) {
+			continue
+		}
+
+		k, err := dict.GetKanji(string(r))
+		if err != nil {
+			log.Fatal("A database error has occured:", err)
+		}
+		if k == nil {
+			continue
+		}
+
+		if found {
+			fmt.Println("--------------------")
+		}
+		found = true
+		printKanji(k)
+
+		if *limit == 0 {
+			continue
+		}
+
+		words, err := dict.Search(k.Literal, dict.JAP, dict.ALL)
+		if err != nil {
+			log.Fatal("A database error has occured:", err)
+		}
+		if len(words) > *limit {
+			words = words[:*limit]
+		}
+
+		if len(words) > 0 {
+			fmt.Println("\nWords:")
+		}
+		for i, w := range words {
+			fmt.Printf("%d: ", i+1)
+			printWord(w)
+		}
+	}
+
+	if !found {
+		log.Fatalf("Could not find any kanji in '%s'\n", strings.Join(flags.Args(), " "))
+	}
+}
+
+func printKanji(k *dict.Kanji) {
+	var info []string
+	if k.Strokes > 0 {
+		info = append(info, fmt.Sprintf("%d strokes", k.Strokes))
+	}
+	if k.Grade > 0 {
+		info = append(info, fmt.Sprintf("grade %d", k.Grade))
+	}
+	if k.JLPT > 0 {
+		info = append(info, fmt.Sprintf("JLPT %d", k.JLPT))
+	}
+	if k.Freq > 0 {
+		info = append(info, fmt.Sprintf("frequency #%d", k.Freq))
+	}
+	fmt.Printf("%s  %s\n", k.Literal, strings.Join(info, ", "))
+
+	for _, l := range []struct {
+		name   string
+		values []string
+	}{
+		{"on", k.On},
+		{"kun", k.Kun},
+		{"nanori", k.Nanori},
+		{"meaning", k.Meanings},
+	} {
+		if len(l.values) > 0 {
+			fmt.Printf("    %-8s %s\n", l.name+":", strings.Join(l.values, ", "))
+		}
+	}
+}
+
+func deinflect(cmd *command, args []string) {
+	if len(args) < 1 {
+		cmd.Usage()
+		os.Exit(2)
+	}
+
+	text := strings.Join(args, "")
+	if isLatin(text) {
+		if kana, ok := romaji.ToKana(text); ok {
+			text = kana
+		}
+	}
+
+	results, err := dict.Deinflect(text)
+	if err != nil {
+		log.Fatal("A database error has occured:", err)
+	}
+	if len(results) == 0 {
+		log.Fatalf("Could not find a dictionary form of '%s'\n", text)
+	}
+
+	for i, r := range results {
+		chain := "dictionary form"
+		if len(r.Chain) > 0 {
+			chain = strings.Join(r.Chain, " → ")
+		}
+
+		fmt.Printf("%d: %s\n", i+1, chain)
+		printWord(r.Word)
 	}
-	word.PrintConjTable()
 }
 
 func test(cmd *command, args []string) {
@@ -82,9 +659,39 @@ func test(cmd *command, args []string) {
 		os.Exit(2)
 	}
 
+	flags := flag.NewFlagSet("test", flag.ExitOnError)
+	flags.Usage = cmd.Usage
+	source := flags.String("source", "dict", "")
+	forms := flags.String("forms", "", "")
+	polarity := flags.String("polarity", "both", "")
+	politeness := flags.String("politeness", "both", "")
+	class := flags.String("class", "", "")
+	weights := flags.String("weights", "", "")
+	common := flags.Bool("common", false, "")
+	jlpt := flags.String("jlpt", "", "")
+	excludeArchaic := flags.Bool("exclude-archaic", false, "")
+	choices := flags.Int("choices", 0, "")
+	flags.Parse(args[1:])
+
+	if (*source != "dict" && *source != "wanikani") || *choices < 0 || *choices == 1 {
+		cmd.Usage()
+		os.Exit(2)
+	}
+
+	opts, err := newQuizOptions(*forms, *polarity, *politeness, *class, *weights)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if opts.criteria.JLPT, err = parseJLPT(*jlpt); err != nil {
+		log.Fatal(err)
+	}
+	opts.criteria.Common = *common
+	opts.criteria.ExcludeArchaic = *excludeArchaic
+	opts.choices = *choices
+
 	n := -1
-	if len(args) > 1 {
-		n, _ = strconv.Atoi(args[1])
+	if flags.NArg() > 0 {
+		n, _ = strconv.Atoi(flags.Arg(0))
 	}
 
 	if n < 0 {
@@ -93,78 +700,553 @@ func test(cmd *command, args []string) {
 
 	switch args[0] {
 	case "conj":
-		test_conj(n)
+		test_conj(n, *source, opts)
 	}
 }
 
-func test_conj(n int) {
-	words := DB_get_random_verbs(n)
+// test_conj asks for n random conjugations chosen by opts of words taken
+// from source, either the whole dictionary or the vocabulary unlocked on
+// WaniKani.
+func test_conj(n int, source string, opts *quizOptions) {
+	SRS_init()
+	defer SRS_close()
+
+	words, err := opts.quizWords(n, source)
+	if err != nil {
+		filterFailed(err)
+	}
+	if len(words) == 0 {
+		if source == "wanikani" {
+			log.Fatal("No matching unlocked WaniKani verbs or adjectives found. Run 'msyu wanikani sync' first.")
+		}
+		log.Fatal("No words found matching the options given.")
+	}
 
-	if words == nil {
-		panic("no verbs found")
+	if quizScreen = openScreen(); quizScreen != nil {
+		defer quizScreen.Close()
 	}
 
 	for _, word := range words {
-		var sPolite string
-		var sPositive string
-
-		randomBytes := make([]byte, 3)
-		_, err := rand.Read(randomBytes)
+		it, err := opts.pick(word)
 		if err != nil {
 			fmt.Println("error:", err)
 			return
 		}
 
-		positive := (int(randomBytes[0]) % 2) == 0
-		polite := (int(randomBytes[1]) % 2) == 0
-		conj := conjugations[int(int(randomBytes[2])%len(conjugations))]
-
-		if polite {
-			sPolite = "Polite"
-		} else {
-			sPolite = "Plain"
+		var correct bool
+		if opts.choices > 0 {
+			if correct, err = ask_choice(it, opts.choices); err == io.EOF {
+				return
+			} else if err != nil {
+				fmt.Println("error:", err)
+				return
+			}
+		} else if correct, err = ask_conj(word, it.conj, it.positive, it.polite); err == io.EOF {
+			return
+		} else if err != nil {
+			fmt.Println("error:", err)
+			return
 		}
 
-		if positive {
-			sPositive = "Positive"
+		if correct {
+			SRS_record(word, it.conj.Name, it.positive, it.polite, gradeCorrect)
 		} else {
-			sPositive = "Negative"
+			SRS_record(word, it.conj.Name, it.positive, it.polite, gradeWrong)
 		}
+	}
+}
 
-		clear()
+// ask_conj asks for the given conjugation of word and tells the user
+// whether the answer was correct. The full-screen interface is used while
+// a test runs in it. io.EOF is returned if stdin is closed before the
+// user moves on to the next question.
+func ask_conj(word *conjugate.Word, conj conjugate.Conjugation, positive bool, polite bool) (bool, error) {
+	if quizScreen != nil {
+		return askScreen(quizScreen, word, conj, positive, polite), nil
+	}
+
+	clear()
+
+	kana, _ := conj.Exec(word, positive, polite)
+	kanji := conj.Kanji(word, positive, polite)
+	fmt.Printf("%s\n\n", formTitle(conj, positive, polite))
+	fmt.Printf("%s (%s)\n\n", word.Kana, strings.Join(word.Kanji, ", "))
+	fmt.Printf("Answer: ")
+
+	input, err := readLine()
+	if err != nil {
+		fmt.Println()
+		return false, err
+	}
 
-		kana, kanji := conj.Exec(word, positive, polite)
-		fmt.Printf("%s - %s / %s\n\n", conj.Name, sPositive, sPolite)
-		fmt.Printf("%s (%s)\n\n", word.kana, strings.Join(word.kanji, ", "))
-		fmt.Printf("Answer: ")
+	clear()
 
-		var input string
-		fmt.Scanf("%s", &input)
+	correct := isAnswer(input, kana, kanji)
+	fmt.Print(conjFeedback(word, conj, positive, polite, input, correct))
+
+	fmt.Printf("\n<Enter> -> Next")
+	if _, err := readLine(); err != nil {
+		fmt.Println()
+		return correct, err
+	}
+	clear()
+
+	return correct, nil
+}
+
+// ask_choice asks for the form of the item with up to n options to choose
+// from and tells the user whether the right one was chosen. The
+// full-screen interface is used while a test runs in it.
+func ask_choice(it *quizItem, n int) (bool, error) {
+	choices, err := it.choices(n)
+	if err != nil {
+		return false, err
+	}
 
+	var chosen choice
+	if quizScreen != nil {
+		chosen = choiceScreen(quizScreen, it, choices)
+	} else {
 		clear()
 
-		if input == kana || input == kanji {
-			fmt.Printf("Correct Answer !\n\n")
-			fmt.Printf("%s - %s / %s\n\n", conj.Name, sPositive, sPolite)
-			fmt.Printf("%s (%s)\n", kana, kanji)
-		} else {
-			fmt.Printf("Wrong Answer !\n\n")
-			fmt.Printf("%s - %s / %s\n\n", conj.Name, sPositive, sPolite)
-			fmt.Printf("Entered: %s\n", input)
-			fmt.Printf("Correct: %s (%s)\n\n", kana, kanji)
-			fmt.Println("Conjugation Rules:")
-
-			// this will make sense in the future, I promise
-			if strings.HasPrefix(word.gloss[0].pos[0], "v1") {
-				fmt.Printf("%s\n", conj.Rule["v1"])
-			} else if strings.HasPrefix(word.gloss[0].pos[0], "v5") {
-				fmt.Printf("%s\n", conj.Rule["v5"])
+		fmt.Printf("%s\n\n", formTitle(it.conj, it.positive, it.polite))
+		fmt.Printf("%s (%s)\n\n", it.word.Kana, strings.Join(it.word.Kanji, ", "))
+		for i, c := range choices {
+			fmt.Printf("  %d) %s\n", i+1, c.text)
+		}
+
+		for {
+			fmt.Printf("\nAnswer (1-%d): ", len(choices))
+			input, err := readLine()
+			if err != nil {
+				fmt.Println()
+				return false, err
+			}
+			if i, err := strconv.Atoi(input); err == nil && i >= 1 && i <= len(choices) {
+				chosen = choices[i-1]
+				break
 			}
-			fmt.Println("\nBase Rules:\n", baseRules)
 		}
+		clear()
+	}
+
+	correct := chosen.mistake == ""
+	input := chosen.text
+	if !correct {
+		input = fmt.Sprintf("%s (%s)", chosen.text, chosen.mistake)
+	}
+	feedback := conjFeedback(it.word, it.conj, it.positive, it.polite, input, correct)
 
+	if quizScreen != nil {
+		feedbackScreen(quizScreen, feedback, correct)
+	} else {
+		fmt.Print(feedback)
 		fmt.Printf("\n<Enter> -> Next")
-		fmt.Scanf("%s", &input)
+		if _, err := readLine(); err != nil {
+			fmt.Println()
+			return correct, err
+		}
 		clear()
 	}
+
+	return correct, nil
+}
+
+// formTitle returns the name of the form together with its polarity and
+// politeness, e.g. "Past Tense - Negative / Polite".
+func formTitle(conj conjugate.Conjugation, positive bool, polite bool) string {
+	var sPolite string
+	var sPositive string
+
+	if polite {
+		sPolite = "Polite"
+	} else {
+		sPolite = "Plain"
+	}
+
+	if positive {
+		sPositive = "Positive"
+	} else {
+		sPositive = "Negative"
+	}
+
+	return fmt.Sprintf("%s - %s / %s", conj.Name, sPositive, sPolite)
+}
+
+// conjFeedback returns the text telling the user whether input was the
+// given conjugation of word. Wrong answers are explained.
+func conjFeedback(word *conjugate.Word, conj conjugate.Conjugation, positive bool, polite bool, input string, correct bool) string {
+	var b strings.Builder
+
+	kana, _ := conj.Exec(word, positive, polite)
+	kanji := conj.Kanji(word, positive, polite)
+	if correct {
+		fmt.Fprintf(&b, "Correct Answer !\n\n")
+		fmt.Fprintf(&b, "%s\n\n", formTitle(conj, positive, polite))
+		fmt.Fprintf(&b, "%s %s\n", answer(kana, kanji), romaji.FromKana(kana, romaji.Hepburn))
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "Wrong Answer !\n\n")
+	fmt.Fprintf(&b, "%s\n\n", formTitle(conj, positive, polite))
+	fmt.Fprintf(&b, "Entered: %s\n", input)
+	fmt.Fprintf(&b, "Correct: %s %s\n\n", answer(kana, kanji), romaji.FromKana(kana, romaji.Hepburn))
+	fmt.Fprintln(&b, "Derivation:")
+	fmt.Fprintf(&b, "%s\n\n", explainForm(word, conj.Form, positive, polite))
+	fmt.Fprintln(&b, "Conjugation Rules:")
+
+	// this will make sense in the future, I promise
+	if strings.HasPrefix(word.Class(), "v1") {
+		fmt.Fprintf(&b, "%s\n", conj.Rule["v1"])
+	} else if strings.HasPrefix(word.Class(), "v5") {
+		fmt.Fprintf(&b, "%s\n", conj.Rule["v5"])
+	} else if strings.HasPrefix(word.Class(), "adj-i") {
+		fmt.Fprintf(&b, "%s\n", conj.Rule["adj-i"])
+	} else if word.Class() == "adj-na" {
+		fmt.Fprintf(&b, "%s\n", conj.Rule["adj-na"])
+	}
+
+	if !word.IsAdjective() {
+		fmt.Fprintln(&b, "\nBase Rules:\n", conjugate.BaseRules)
+	}
+
+	return b.String()
+}
+
+// isAnswer reports whether input is the form with the given kana and
+// kanji spellings, written in kana, any of the kanji spellings or romaji.
+func isAnswer(input string, kana string, kanji []string) bool {
+	correct := input == kana
+	for _, k := range kanji {
+		correct = correct || input == k
+	}
+	if r, ok := romaji.ToKana(input); ok && !correct {
+		correct = r == kana
+	}
+	return correct
+}
+
+// answer returns the kana of a form followed by its kanji spellings with
+// furigana.
+func answer(kana string, kanji []string) string {
+	if len(kanji) == 0 {
+		return kana
+	}
+
+	var spellings []string
+	for _, k := range kanji {
+		spellings = append(spellings, furigana(k, kana))
+	}
+	return fmt.Sprintf("%s (%s)", kana, strings.Join(spellings, ", "))
+}
+
+// explainForm returns the derivation of the given form of word.
+func explainForm(word *conjugate.Word, form conjugate.Form, positive bool, polite bool) string {
+	polarity, politeness := conjugate.Positive, conjugate.Plain
+	if !positive {
+		polarity = conjugate.Negative
+	}
+	if polite {
+		politeness = conjugate.Polite
+	}
+
+	trace, err := conjugate.Explain(word, form, polarity, politeness)
+	if err != nil {
+		return ""
+	}
+	return trace.String()
+}
+
+func review(cmd *command, args []string) {
+	n := -1
+	if len(args) > 0 {
+		n, _ = strconv.Atoi(args[0])
+	}
+
+	if n < 0 {
+		n = 25
+	}
+
+	SRS_init()
+	defer SRS_close()
+
+	items := SRS_get_due_items(n)
+	if len(items) == 0 {
+		fmt.Println("Nothing to review")
+		return
+	}
+
+	if quizScreen = openScreen(); quizScreen != nil {
+		defer quizScreen.Close()
+	}
+
+	for _, it := range items {
+		word, err := dict.GetWord(it.word, it.kana)
+		if err != nil {
+			log.Fatal("A database error has occured:", err)
+		}
+		if word == nil {
+			continue
+		}
+
+		var conj *conjugate.Conjugation
+		conjs := word.Conjugations()
+		for i := range conjs {
+			if conjs[i].Name == it.conj {
+				conj = &conjs[i]
+			}
+		}
+		if conj == nil {
+			continue
+		}
+
+		correct, err := ask_conj(word, *conj, it.positive, it.polite)
+		if err == io.EOF {
+			return
+		} else if err != nil {
+			fmt.Println("error:", err)
+			return
+		}
+
+		if correct {
+			SRS_record(word, conj.Name, it.positive, it.polite, gradeCorrect)
+		} else {
+			SRS_record(word, conj.Name, it.positive, it.polite, gradeWrong)
+		}
+	}
+}
+
+func export(cmd *command, args []string) {
+	if len(args) < 1 || args[0] != "anki" {
+		cmd.Usage()
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	flags.Usage = cmd.Usage
+	out := flags.String("o", "msyu.apkg", "")
+	name := flags.String("deck", "msyu", "")
+	vocab := flags.Bool("vocab", false, "")
+	conj := flags.Bool("conj", false, "")
+	class := flags.String("class", "", "")
+	formNames := flags.String("forms", "", "")
+	all := flags.Bool("all", false, "")
+	flags.Parse(args[1:])
+	args = flags.Args()
+
+	if !*all && len(args) < 1 {
+		cmd.Usage()
+		os.Exit(2)
+	}
+	if !*vocab && !*conj {
+		*vocab, *conj = true, true
+	}
+
+	forms, err := parseForms(*formNames)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	filter := dict.CONJUGABLE
+	if *vocab {
+		filter = dict.ALL
+	}
+
+	var words []*conjugate.Word
+	if *all {
+		if words, err = dict.AllWords(filter); err != nil {
+			log.Fatal("A database error has occured:", err)
+		}
+	}
+	for _, arg := range args {
+		found, err := dict.Lookup(arg, filter)
+		if kana, ok := romaji.ToKana(arg); ok && err == nil && len(found) == 0 {
+			found, err = dict.Lookup(kana, filter)
+		}
+		if err != nil {
+			log.Fatal("A database error has occured:", err)
+		}
+		if len(found) == 0 {
+			log.Fatalf("Could not find word '%s'\n", arg)
+		}
+		words = append(words, found...)
+	}
+
+	var classes []string
+	if *class != "" {
+		classes = strings.Split(*class, ",")
+	}
+
+	deck := anki.NewDeck(*name)
+	for _, w := range words {
+		if classes != nil && !hasPos(w, classes) {
+			continue
+		}
+
+		if *vocab {
+			if err := addVocabNote(deck, w); err != nil {
+				log.Fatal("A database error has occured:", err)
+			}
+		}
+		if *conj {
+			addConjNotes(deck, w, forms)
+		}
+	}
+
+	if err := deck.WriteFile(*out); err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Exported %d notes to %s\n", len(deck.Notes), *out)
+}
+
+func serve(cmd *command, args []string) {
+	flags := flag.NewFlagSet("serve", flag.ExitOnError)
+	flags.Usage = cmd.Usage
+	addr := flags.String("addr", ":8080", "")
+	flags.Parse(args)
+
+	fmt.Printf("Listening on %s\n", *addr)
+	log.Fatal(http.ListenAndServe(*addr, newServeMux()))
+}
+
+func wanikani_cmd(cmd *command, args []string) {
+	if len(args) < 1 || args[0] != "sync" {
+		cmd.Usage()
+		os.Exit(2)
+	}
+
+	flags := flag.NewFlagSet("wanikani", flag.ExitOnError)
+	flags.Usage = cmd.Usage
+	token := flags.String("token", os.Getenv("WANIKANI_TOKEN"), "")
+	baseURL := flags.String("url", os.Getenv("WANIKANI_URL"), "")
+	flags.Parse(args[1:])
+
+	if *token == "" {
+		log.Fatal("A WaniKani API token is needed, given with -token or $WANIKANI_TOKEN")
+	}
+
+	client := wanikani.NewClient(*baseURL, *token)
+	assignments, err := client.UnlockedVocabulary()
+	if err != nil {
+		log.Fatal("Could not fetch the WaniKani assignments: ", err)
+	}
+	subjects, err := client.Vocabulary()
+	if err != nil {
+		log.Fatal("Could not fetch the WaniKani vocabulary: ", err)
+	}
+
+	unlocked := make(map[int]bool)
+	for _, a := range assignments {
+		unlocked[a.SubjectID] = true
+	}
+
+	words := make(map[int][]*conjugate.Word)
+	matched, conjugable := 0, 0
+	for _, s := range subjects {
+		if !unlocked[s.ID] {
+			continue
+		}
+
+		ws, err := wanikaniMatches(s)
+		if err != nil {
+			log.Fatal("A database error has occured:", err)
+		}
+		if len(ws) == 0 {
+			continue
+		}
+
+		words[s.ID] = ws
+		matched++
+		for _, w := range ws {
+			if w.Conjugations() != nil {
+				conjugable++
+				break
+			}
+		}
+	}
+
+	SRS_init()
+	defer SRS_close()
+	WK_store(words)
+
+	fmt.Printf("%d of %d unlocked vocabulary found in the dictionary, %d of them verbs or adjectives\n",
+		matched, len(unlocked), conjugable)
+}
+
+func import_dict(cmd *command, args []string) {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	flags.Usage = cmd.Usage
+	accents := flags.Bool("accents", false, "")
+	kanjidic := flags.Bool("kanjidic", false, "")
+	examples := flags.Bool("examples", false, "")
+	jlpt := flags.Bool("jlpt", false, "")
+	flags.Parse(args)
+	args = flags.Args()
+
+	if len(args) < 1 {
+		cmd.Usage()
+		os.Exit(2)
+	}
+
+	if *examples {
+		if len(args) < 2 {
+			cmd.Usage()
+			os.Exit(2)
+		}
+
+		var links string
+		if len(args) > 2 {
+			links = args[2]
+		}
+
+		n, err := dict.ImportExamples(args[0], args[1], links, func(n int) {
+			fmt.Printf("\r%d examples imported", n)
+		})
+		if err != nil {
+			log.Fatal("Import failed: ", err)
+		}
+		fmt.Printf("\r%d examples imported\n", n)
+		return
+	}
+
+	if *kanjidic {
+		n, err := dict.ImportKanjidic(args[0], func(n int) {
+			fmt.Printf("\r%d kanji imported", n)
+		})
+		if err != nil {
+			log.Fatal("Import failed: ", err)
+		}
+		fmt.Printf("\r%d kanji imported\n", n)
+		return
+	}
+
+	if *jlpt {
+		n, err := dict.ImportJLPT(args[0], func(n int) {
+			fmt.Printf("\r%d lines read", n)
+		})
+		if err != nil {
+			log.Fatal("Import failed: ", err)
+		}
+		fmt.Printf("\r%d readings with a JLPT level imported\n", n)
+		return
+	}
+
+	if *accents {
+		n, err := dict.ImportAccents(args[0], func(n int) {
+			fmt.Printf("\r%d lines read", n)
+		})
+		if err != nil {
+			log.Fatal("Import failed: ", err)
+		}
+		fmt.Printf("\r%d accents imported\n", n)
+		return
+	}
+
+	n, err := dict.ImportJMdict(args[0], func(n int) {
+		fmt.Printf("\r%d entries imported", n)
+	})
+	if err != nil {
+		log.Fatal("Import failed: ", err)
+	}
+	fmt.Printf("\r%d entries imported\n", n)
 }
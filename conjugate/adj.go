@@ -0,0 +1,375 @@
+package conjugate
+
+import (
+	"strings"
+)
+
+var AdjConjugations = []Conjugation{
+	{
+		Form: Present,
+		Exec: (*Word).ToAdjPresent,
+		Name: "Present Tense",
+		Rule: map[string]string{
+			"adj-i": `* Positive Plain:  辞書形
+* Positive Polite: 辞書形 + です
+* Negative Plain:  語幹 + くない
+* Negative Polite: 語幹 + くありません`,
+			"adj-na": `* Positive Plain:  語幹 + だ
+* Positive Polite: 語幹 + です
+* Negative Plain:  語幹 + ではない
+* Negative Polite: 語幹 + ではありません`,
+		},
+	},
+	{
+		Form: Past,
+		Exec: (*Word).ToAdjPast,
+		Name: "Past Tense",
+		Rule: map[string]string{
+			"adj-i": `* Positive Plain:  語幹 + かった
+* Positive Polite: 語幹 + かったです
+* Negative Plain:  語幹 + くなかった
+* Negative Polite: 語幹 + くありませんでした`,
+			"adj-na": `* Positive Plain:  語幹 + だった
+* Positive Polite: 語幹 + でした
+* Negative Plain:  語幹 + ではなかった
+* Negative Polite: 語幹 + ではありませんでした`,
+		},
+	},
+	{
+		Form: TeForm,
+		Exec: (*Word).ToAdjTeForm,
+		Name: "Te Form",
+		Rule: map[string]string{
+			"adj-i": `* Positive Plain:  語幹 + くて
+* Positive Polite: 語幹 + くて
+* Negative Plain:  語幹 + くなくて
+* Negative Polite: 語幹 + くありませんで`,
+			"adj-na": `* Positive Plain:  語幹 + で
+* Positive Polite: 語幹 + で
+* Negative Plain:  語幹 + ではなくて
+* Negative Polite: 語幹 + ではありませんで`,
+		},
+	},
+	{
+		Form: Conditional,
+		Exec: (*Word).ToAdjConditional,
+		Name: "Conditional",
+		Rule: map[string]string{
+			"adj-i": `* Positive Plain:  語幹 + かったら
+* Positive Polite: 語幹 + かったら
+* Negative Plain:  語幹 + くなかったら
+* Negative Polite: 語幹 + くありませんでしたら`,
+			"adj-na": `* Positive Plain:  語幹 + だったら
+* Positive Polite: 語幹 + でしたら
+* Negative Plain:  語幹 + ではなかったら
+* Negative Polite: 語幹 + ではありませんでしたら`,
+		},
+	},
+	{
+		Form: Provisional,
+		Exec: (*Word).ToAdjProvisional,
+		Name: "Provisional",
+		Rule: map[string]string{
+			"adj-i": `* Positive Plain:  語幹 + ければ
+* Positive Polite: 語幹 + ければ
+* Negative Plain:  語幹 + くなければ
+* Negative Polite: 語幹 + くありませんなら`,
+			"adj-na": `* Positive Plain:  語幹 + なら
+* Positive Polite: 語幹 + なら
+* Negative Plain:  語幹 + でなければ
+* Negative Polite: 語幹 + ではありませんなら`,
+		},
+	},
+	{
+		Form: Conjectural,
+		Exec: (*Word).ToAdjConjectural,
+		Name: "Conjectural",
+		Rule: map[string]string{
+			"adj-i": `* Positive Plain:  辞書形 + だろう
+* Positive Polite: 辞書形 + でしょう
+* Negative Plain:  語幹 + くないだろう
+* Negative Polite: 語幹 + くないでしょう`,
+			"adj-na": `* Positive Plain:  語幹 + だろう
+* Positive Polite: 語幹 + でしょう
+* Negative Plain:  語幹 + ではないだろう
+* Negative Polite: 語幹 + ではないでしょう`,
+		},
+	},
+	{
+		Form: Adverbial,
+		Exec: (*Word).ToAdjAdverbial,
+		Name: "Adverbial",
+		Rule: map[string]string{
+			"adj-i": `* Positive Plain:  語幹 + く
+* Positive Polite: 語幹 + く
+* Negative Plain:  語幹 + くなく
+* Negative Polite: 語幹 + くなく`,
+			"adj-na": `* Positive Plain:  語幹 + に
+* Positive Polite: 語幹 + に
+* Negative Plain:  語幹 + ではなく
+* Negative Polite: 語幹 + ではなく`,
+		},
+	},
+}
+
+// IsAdjective reports whether the word is an i- or na-adjective.
+func (w *Word) IsAdjective() bool {
+	return strings.HasPrefix(w.Class(), "adj-")
+}
+
+// Conjugations returns the conjugations applicable to the word, nil if
+// it doesn't conjugate.
+func (w *Word) Conjugations() []Conjugation {
+	if w.Class() == "" {
+		return nil
+	} else if w.IsAdjective() {
+		return AdjConjugations
+	}
+	return VerbConjugations
+}
+
+// conjunction ------------
+func (w *Word) ToAdjPresent(positive bool, formal bool) (string, string) {
+	var ending string
+	kana, kanji := w.ToAdjStem()
+
+	if w.Class() == "adj-na" {
+		if positive {
+			if formal {
+				ending = "です"
+			} else {
+				ending = "だ"
+			}
+		} else {
+			if formal {
+				ending = "ではありません"
+			} else {
+				ending = "ではない"
+			}
+		}
+	} else {
+		if positive {
+			kana, kanji = w.Kana, w.Kanji[0]
+			if formal {
+				ending = "です"
+			}
+		} else {
+			if formal {
+				ending = "くありません"
+			} else {
+				ending = "くない"
+			}
+		}
+	}
+
+	return w.attach(kana, kanji, ending)
+}
+
+func (w *Word) ToAdjPast(positive bool, formal bool) (string, string) {
+	var ending string
+	kana, kanji := w.ToAdjStem()
+
+	if w.Class() == "adj-na" {
+		if positive {
+			if formal {
+				ending = "でした"
+			} else {
+				ending = "だった"
+			}
+		} else {
+			if formal {
+				ending = "ではありませんでした"
+			} else {
+				ending = "ではなかった"
+			}
+		}
+	} else {
+		if positive {
+			if formal {
+				ending = "かったです"
+			} else {
+				ending = "かった"
+			}
+		} else {
+			if formal {
+				ending = "くありませんでした"
+			} else {
+				ending = "くなかった"
+			}
+		}
+	}
+
+	return w.attach(kana, kanji, ending)
+}
+
+func (w *Word) ToAdjTeForm(positive bool, formal bool) (string, string) {
+	var ending string
+	kana, kanji := w.ToAdjStem()
+
+	if w.Class() == "adj-na" {
+		if positive {
+			ending = "で"
+		} else {
+			if formal {
+				ending = "ではありませんで"
+			} else {
+				ending = "ではなくて"
+			}
+		}
+	} else {
+		if positive {
+			ending = "くて"
+		} else {
+			if formal {
+				ending = "くありませんで"
+			} else {
+				ending = "くなくて"
+			}
+		}
+	}
+
+	return w.attach(kana, kanji, ending)
+}
+
+func (w *Word) ToAdjConditional(positive bool, formal bool) (string, string) {
+	var ending string
+	kana, kanji := w.ToAdjStem()
+
+	if w.Class() == "adj-na" {
+		if positive {
+			if formal {
+				ending = "でしたら"
+			} else {
+				ending = "だったら"
+			}
+		} else {
+			if formal {
+				ending = "ではありませんでしたら"
+			} else {
+				ending = "ではなかったら"
+			}
+		}
+	} else {
+		if positive {
+			ending = "かったら"
+		} else {
+			if formal {
+				ending = "くありませんでしたら"
+			} else {
+				ending = "くなかったら"
+			}
+		}
+	}
+
+	return w.attach(kana, kanji, ending)
+}
+
+func (w *Word) ToAdjProvisional(positive bool, formal bool) (string, string) {
+	var ending string
+	kana, kanji := w.ToAdjStem()
+
+	if w.Class() == "adj-na" {
+		if positive {
+			ending = "なら"
+		} else {
+			if formal {
+				ending = "ではありませんなら"
+			} else {
+				ending = "でなければ"
+			}
+		}
+	} else {
+		if positive {
+			ending = "ければ"
+		} else {
+			if formal {
+				ending = "くありませんなら"
+			} else {
+				ending = "くなければ"
+			}
+		}
+	}
+
+	return w.attach(kana, kanji, ending)
+}
+
+func (w *Word) ToAdjConjectural(positive bool, formal bool) (string, string) {
+	var ending string
+	kana, kanji := w.ToAdjStem()
+
+	if w.Class() == "adj-na" {
+		if positive {
+			if formal {
+				ending = "でしょう"
+			} else {
+				ending = "だろう"
+			}
+		} else {
+			if formal {
+				ending = "ではないでしょう"
+			} else {
+				ending = "ではないだろう"
+			}
+		}
+	} else {
+		if positive {
+			kana, kanji = w.Kana, w.Kanji[0]
+			if formal {
+				ending = "でしょう"
+			} else {
+				ending = "だろう"
+			}
+		} else {
+			if formal {
+				ending = "くないでしょう"
+			} else {
+				ending = "くないだろう"
+			}
+		}
+	}
+
+	return w.attach(kana, kanji, ending)
+}
+
+func (w *Word) ToAdjAdverbial(positive bool, formal bool) (string, string) {
+	var ending string
+	kana, kanji := w.ToAdjStem()
+
+	if w.Class() == "adj-na" {
+		if positive {
+			ending = "に"
+		} else {
+			ending = "ではなく"
+		}
+	} else {
+		if positive {
+			ending = "く"
+		} else {
+			ending = "くなく"
+		}
+	}
+
+	return w.attach(kana, kanji, ending)
+}
+
+// Inflection bases -------------
+
+// ToAdjStem returns the adjective without its trailing い. The stem of
+// いい is よ. na-adjectives are their own stem.
+func (w *Word) ToAdjStem() (string, string) {
+	if w.Class() == "adj-na" {
+		return w.Kana, w.Kanji[0]
+	}
+
+	stem, kstem := strings.TrimSuffix(w.Kana, "い"), ""
+	if strings.HasSuffix(w.Kanji[0], "い") {
+		// kanji spellings without okurigana can't be inflected
+		kstem = strings.TrimSuffix(w.Kanji[0], "い")
+	}
+	if w.Class() == "adj-ix" {
+		stem, kstem = swapLast(stem, "い", "よ"), swapLast(kstem, "い", "よ")
+	}
+
+	w.noteBase("語幹", stem)
+	return stem, kstem
+}
@@ -0,0 +1,217 @@
+package conjugate
+
+import (
+	"errors"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrUnknownStep  = errors.New("conjugate: unknown chain step")
+	ErrInvalidChain = errors.New("conjugate: chain ends in more than one form")
+)
+
+// Step derives a new word from another one, e.g. the causative 書かせる
+// from 書く. The derived word conjugates like any other word of its class,
+// so steps can be stacked and the result put into any form.
+type Step struct {
+	Name  string
+	Apply func(*Word) *Word
+	// Verb is set for steps that only apply to verbs
+	Verb bool
+}
+
+var Steps = []Step{
+	{Name: "causative", Apply: (*Word).Causative, Verb: true},
+	{Name: "potential", Apply: (*Word).Potential, Verb: true},
+	{Name: "passive", Apply: (*Word).Passive, Verb: true},
+	{Name: "desiderative", Apply: (*Word).Desiderative, Verb: true},
+	{Name: "progressive", Apply: (*Word).Progressive, Verb: true},
+	{Name: "shimau", Apply: (*Word).TeShimau, Verb: true},
+}
+
+// chain names of the forms a chain may end in
+var chainForms = map[string]Form{
+	"present":     Present,
+	"past":        Past,
+	"te":          TeForm,
+	"conditional": Conditional,
+	"provisional": Provisional,
+	"conjectural": Conjectural,
+	"alternative": Alternative,
+	"imperative":  Imperative,
+	"adverbial":   Adverbial,
+	"volitional":  Volitional,
+	"zu":          Zu,
+	"zuni":        Zuni,
+}
+
+// Chain is a stack of derivation steps followed by the form, polarity and
+// politeness the derived word is put into.
+type Chain struct {
+	Steps      []Step
+	Form       Form
+	Polarity   Polarity
+	Politeness Politeness
+}
+
+// ParseChain parses a comma separated chain like
+// "causative,passive,past,negative". Step names are applied in the given
+// order, at most one form name selects the final form (present if there is
+// none) and "negative" and "polite" select its polarity and politeness.
+func ParseChain(s string) (*Chain, error) {
+	c := &Chain{Form: Present}
+	hasForm := false
+
+	for _, name := range strings.Split(s, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if f, ok := chainForms[name]; ok {
+			if hasForm {
+				return nil, ErrInvalidChain
+			}
+			c.Form, hasForm = f, true
+			continue
+		}
+
+		switch name {
+		case "polite":
+			c.Politeness = Polite
+			continue
+		case "negative":
+			c.Polarity = Negative
+			continue
+		}
+
+		step, ok := findStep(name)
+		if !ok {
+			return nil, ErrUnknownStep
+		}
+		c.Steps = append(c.Steps, step)
+	}
+
+	return c, nil
+}
+
+func findStep(name string) (Step, bool) {
+	for _, s := range Steps {
+		if s.Name == name {
+			return s, true
+		}
+	}
+	return Step{}, false
+}
+
+// Derive applies the chain's steps to w and returns w followed by every
+// derived word.
+func (c *Chain) Derive(w *Word) ([]*Word, error) {
+	words := []*Word{w}
+
+	for _, s := range c.Steps {
+		if w.Class() == "" || (s.Verb && w.IsAdjective()) {
+			return nil, ErrUnknownForm
+		}
+		w = s.Apply(w)
+		words = append(words, w)
+	}
+
+	return words, nil
+}
+
+// Apply applies the chain to w and returns the kana and kanji spelling of
+// the result.
+func (c *Chain) Apply(w *Word) (string, string, error) {
+	words, err := c.Derive(w)
+	if err != nil {
+		return "", "", err
+	}
+	return Conjugate(words[len(words)-1], c.Form, c.Polarity, c.Politeness)
+}
+
+// Causative returns the causative of the verb, an ichidan verb: 書かせる.
+func (w *Word) Causative() *Word {
+	kana, kanji, infix := w.ToVoiceStem("さ")
+	return w.derive(kana, kanji, infix+"せる", "v1")
+}
+
+// Passive returns the passive of the verb, an ichidan verb: 書かれる. For
+// ichidan verbs it doubles as their potential.
+func (w *Word) Passive() *Word {
+	kana, kanji, infix := w.ToVoiceStem("ら")
+	return w.derive(kana, kanji, infix+"れる", "v1")
+}
+
+// Potential returns the potential of the verb, an ichidan verb: 書ける,
+// 食べられる. The potential of する is できる.
+func (w *Word) Potential() *Word {
+	switch c := w.Class(); {
+	case c == "v5r-i":
+		// ある: あり得る
+		kana, kanji := w.ToRenyoukei()
+		return w.derive(kana, kanji, "える", "v1")
+	case c == "vs-s":
+		// 愛する: 愛せる
+		stem, kstem := w.ToStem()
+		stem, kstem = swapLast(stem, "す", "せ"), swapLast(kstem, "す", "せ")
+		w.noteBase("已然形", stem)
+		return w.derive(stem, kstem, "る", "v1")
+	case w.isSuru():
+		kana, kanji := w.ToRentaikei()
+		kana = trimLast(kana, 2)
+		if kanji != "" {
+			kanji = trimLast(kanji, 2)
+		}
+		return w.derive(kana, kanji, "できる", "v1")
+	case w.isIchidan():
+		return w.Passive()
+	}
+
+	kana, kanji := w.ToIzenkei()
+	return w.derive(kana, kanji, "る", "v1")
+}
+
+// Desiderative returns the verb's form expressing a wish, an i-adjective:
+// 書きたい.
+func (w *Word) Desiderative() *Word {
+	kana, kanji := w.ToRenyoukei()
+	return w.derive(kana, kanji, "たい", "adj-i")
+}
+
+// Progressive returns the te-form followed by いる, an ichidan verb:
+// 書いている.
+func (w *Word) Progressive() *Word {
+	kana, kanji := w.ToTeForm(true, false)
+	return w.derive(kana, kanji, "いる", "v1")
+}
+
+// TeShimau returns the te-form followed by しまう, a godan verb:
+// 書いてしまう.
+func (w *Word) TeShimau() *Word {
+	kana, kanji := w.ToTeForm(true, false)
+	return w.derive(kana, kanji, "しまう", "v5u")
+}
+
+// derive returns a word of the given class spelled base + ending. It keeps
+// the id and trace of w.
+func (w *Word) derive(kana string, kanji string, ending string, class string) *Word {
+	kana, kanji = w.attach(kana, kanji, ending)
+	d := &Word{
+		ID:    w.ID,
+		Kana:  kana,
+		Kanji: []string{kanji},
+		Gloss: []*Gloss{{Pos: []string{class}}},
+		trace: w.trace,
+	}
+
+	d.trace.word(d)
+	return d
+}
+
+// trimLast returns s without its last n runes.
+func trimLast(s string, n int) string {
+	for ; n > 0 && s != ""; n-- {
+		_, size := utf8.DecodeLastRuneInString(s)
+		s = s[:len(s)-size]
+	}
+	return s
+}
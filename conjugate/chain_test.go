@@ -0,0 +1,59 @@
+package conjugate
+
+import (
+	"testing"
+)
+
+func TestChain(t *testing.T) {
+	tests := []struct {
+		word  string
+		class string
+		chain string
+		kana  string
+		kanji string
+	}{
+		{"書く(かく)", "v5k", "causative,passive,past,negative", "かかせられなかった", "書かせられなかった"},
+		{"書く(かく)", "v5k", "causative,passive,past,negative,polite", "かかせられませんでした", "書かせられませんでした"},
+		{"食べる(たべる)", "v1", "passive,te", "たべられて", "食べられて"},
+		{"食べる(たべる)", "v1", "causative,conditional,polite", "たべさせましたら", "食べさせましたら"},
+		{"来る(くる)", "vk", "causative,passive", "こさせられる", "来させられる"},
+		{"する", "vs-i", "causative,imperative", "させろ", ""},
+		{"勉強(べんきょう)", "vs", "passive,negative", "べんきょうされない", "勉強されない"},
+		{"高い(たかい)", "adj-i", "adverbial,negative", "たかくなく", "高くなく"},
+		{"読む(よむ)", "v5m", "past", "よんだ", "読んだ"},
+		{"書く(かく)", "v5k", "potential,negative,past", "かけなかった", "書けなかった"},
+		{"書く(かく)", "v5k", "desiderative,past,polite", "かきたかったです", "書きたかったです"},
+		{"書く(かく)", "v5k", "progressive,polite,past", "かいていました", "書いていました"},
+		{"書く(かく)", "v5k", "shimau,past", "かいてしまった", "書いてしまった"},
+		{"食べる(たべる)", "v1", "causative,desiderative,negative", "たべさせたくない", "食べさせたくない"},
+		{"する", "vs-i", "potential,volitional", "できよう", ""},
+		{"勉強(べんきょう)", "vs", "zuni,negative", "べんきょうせずに", "勉強せずに"},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseChain(tt.chain)
+		if err != nil {
+			t.Errorf("%s: %v", tt.chain, err)
+			continue
+		}
+
+		kana, kanji, err := c.Apply(fixture(tt.class, tt.word))
+		if err != nil || kana != tt.kana || kanji != tt.kanji {
+			t.Errorf("%s %s: got %s, %s, %v, want %s, %s", tt.word, tt.chain, kana, kanji, err, tt.kana, tt.kanji)
+		}
+	}
+}
+
+func TestChainErrors(t *testing.T) {
+	if _, err := ParseChain("causative,frobnicate"); err != ErrUnknownStep {
+		t.Errorf("unknown step: got %v", err)
+	}
+	if _, err := ParseChain("past,te"); err != ErrInvalidChain {
+		t.Errorf("two forms: got %v", err)
+	}
+
+	c, _ := ParseChain("causative")
+	if _, _, err := c.Apply(fixture("adj-i", "高い(たかい)")); err != ErrUnknownForm {
+		t.Errorf("causative adjective: got %v", err)
+	}
+}
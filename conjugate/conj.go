@@ -0,0 +1,951 @@
+package conjugate
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	BaseRules = `  　　         一段　　　        五段
+  * 語幹:　  remove last る    remove last syllable
+  * 未然形:  語幹              replace last vowel with あ-vowel
+  * 連用形:  語幹              replace last vowel with い-vowel
+  * 連体形:  - 　              -
+  * 已然形:  語幹+る 　        replace last vowel with え-vowel
+  * 命令形:  語幹   　　       replace last vowel with え-vowel`
+)
+
+var VerbConjugations = []Conjugation{
+	{
+		Form: Present,
+		Exec: (*Word).ToPresent,
+		Name: "Present Tense",
+		Rule: map[string]string{
+			"v1": `* Positive Plain:  連体形 
+* Positive Polite: 連用形 + ます
+* Negative Plain:  未然形 + ない
+* Negative Polite: 連用形 + ません`,
+			"v5": `* Positive Plain:  連体形 
+* Positive Polite: 連用形 + ます
+* Negative Plain:  未然形 + ない
+* Negative Polite: 連用形 + ません`,
+		},
+	},
+	{
+		Form: Past,
+		Exec: (*Word).ToPast,
+		Name: "Past Tense",
+		Rule: map[string]string{
+			"v1": `* Positive Plain:  連用形 + た
+* Positive Polite: 連用形 + ました
+* Negative Plain:  未然形 + なかった
+* Negative Polite: 連用形 + ませんでした`,
+			"v5": `* Positive Plain:  連用形 + た *
+* Positive Polite: 連用形 + ました
+* Negative Plain:  未然形 + なかった
+* Negative Polite: 連用形 + ませんでした`,
+		},
+	},
+	{
+		Form: TeForm,
+		Exec: (*Word).ToTeForm,
+		Name: "Te Form",
+		Rule: map[string]string{
+			"v1": `* Positive Plain:  連用形 + て
+* Positive Polite: 連用形 + まして
+* Negative Plain:  未然形 + ないで
+* Negative Polite: 連用形 + ませんで`,
+			"v5": `* Positive Plain:  連用形 + て *
+* Positive Polite: 連用形 + まして
+* Negative Plain:  未然形 + ないで
+* Negative Polite: 連用形 + ませんで`,
+		},
+	},
+	{
+		Form: Conditional,
+		Exec: (*Word).ToConditional,
+		Name: "Conditional",
+		Rule: map[string]string{
+			"v1": `* Positive Plain:  連用形 + たら
+* Positive Polite: 連用形 + ましたら
+* Negative Plain:  未然形 + なかったら
+* Negative Polite: 連用形 + ませんでしたら`,
+			"v5": `* Positive Plain:  連用形 + たら *
+* Positive Polite: 連用形 + ましたら
+* Negative Plain:  未然形 + なかったら
+* Negative Polite: 連用形 + ませんでしたら`,
+		},
+	},
+	{
+		Form: Provisional,
+		Exec: (*Word).ToProvisional,
+		Name: "Provisional",
+		Rule: map[string]string{
+			"v1": `* Positive Plain:  已然形 + ば
+* Positive Polite: 連用形 + ますなら
+* Negative Plain:  未然形 + なければ 
+* Negative Polite: 連用形 + ませんなら`,
+			"v5": `* Positive Plain:  已然形 + ば
+* Positive Polite: 連用形 + ますなら
+* Negative Plain:  未然形 + なければ 
+* Negative Polite: 連用形 + ませんなら`,
+		},
+	},
+	{
+		Form: Volitional,
+		Exec: (*Word).ToVolitional,
+		Name: "Volitional",
+		Rule: map[string]string{
+			"v1": `* Positive Plain:  未然形 + よう
+* Positive Polite: 連用形 + ましょう
+* Negative Plain:  連体形 + まい
+* Negative Polite: 連用形 + ますまい`,
+			"v5": `* Positive Plain:  replace last vowel with お-vowel + う
+* Positive Polite: 連用形 + ましょう
+* Negative Plain:  連体形 + まい
+* Negative Polite: 連用形 + ますまい`,
+		},
+	},
+	{
+		Form: Potential,
+		Exec: (*Word).ToPotential,
+		Name: "Potential",
+		Rule: map[string]string{
+			"v1": `* Positive Plain:  未然形 + られる
+* Positive Polite: 未然形 + られます
+* Negative Plain:  未然形 + られない
+* Negative Polite: 未然形 + られません
+  する becomes できる`,
+			"v5": `* Positive Plain:  已然形 + る
+* Positive Polite: 已然形 + ます
+* Negative Plain:  已然形 + ない
+* Negative Polite: 已然形 + ません`,
+		},
+	},
+	{
+		Form: Passive,
+		Exec: (*Word).ToPassive,
+		Name: "Passive",
+		Rule: map[string]string{
+			"v1": `* Positive Plain:  未然形 + られる
+* Positive Polite: 未然形 + られます
+* Negative Plain:  未然形 + られない
+* Negative Polite: 未然形 + られません`,
+			"v5": `* Positive Plain:  未然形 + れる
+* Positive Polite: 未然形 + れます
+* Negative Plain:  未然形 + れない
+* Negative Polite: 未然形 + れません`,
+		},
+	},
+	{
+		Form: Causative,
+		Exec: (*Word).ToCausative,
+		Name: "Causative",
+		Rule: map[string]string{
+			"v1": `* Positive Plain:  未然形 + させる
+* Positive Polite: 未然形 + させます
+* Negative Plain:  未然形 + させない
+* Negative Polite: 未然形 + させません`,
+			"v5": `* Positive Plain:  未然形 + せる
+* Positive Polite: 未然形 + せます
+* Negative Plain:  未然形 + せない
+* Negative Polite: 未然形 + せません`,
+		},
+	},
+	{
+		Form: CausativePassive,
+		Exec: (*Word).ToCausativePassive,
+		Name: "Causative Passive",
+		Rule: map[string]string{
+			"v1": `* Positive Plain:  未然形 + させられる
+* Positive Polite: 未然形 + させられます
+* Negative Plain:  未然形 + させられない 
+* Negative Polite: 未然形 + させられません`,
+			"v5": `* Positive Plain:  未然形 + せられる
+* Positive Polite: 未然形 + せられます
+* Negative Plain:  未然形 + せられない
+* Negative Polite: 未然形 + せられません`,
+		},
+	},
+	{
+		Form: Desiderative,
+		Exec: (*Word).ToDesiderative,
+		Name: "Desiderative",
+		Rule: map[string]string{
+			"v1": `* Positive Plain:  連用形 + たい
+* Positive Polite: 連用形 + たいです
+* Negative Plain:  連用形 + たくない
+* Negative Polite: 連用形 + たくありません`,
+			"v5": `* Positive Plain:  連用形 + たい
+* Positive Polite: 連用形 + たいです
+* Negative Plain:  連用形 + たくない
+* Negative Polite: 連用形 + たくありません`,
+		},
+	},
+	{
+		Form: Progressive,
+		Exec: (*Word).ToProgressive,
+		Name: "Progressive",
+		Rule: map[string]string{
+			"v1": `* Positive Plain:  連用形 + ている
+* Positive Polite: 連用形 + ています
+* Negative Plain:  連用形 + ていない
+* Negative Polite: 連用形 + ていません`,
+			"v5": `* Positive Plain:  連用形 + ている *
+* Positive Polite: 連用形 + ています *
+* Negative Plain:  連用形 + ていない *
+* Negative Polite: 連用形 + ていません *`,
+		},
+	},
+	{
+		Form: TeShimau,
+		Exec: (*Word).ToTeShimau,
+		Name: "Te Shimau",
+		Rule: map[string]string{
+			"v1": `* Positive Plain:  連用形 + てしまう
+* Positive Polite: 連用形 + てしまいます
+* Negative Plain:  連用形 + てしまわない
+* Negative Polite: 連用形 + てしまいません`,
+			"v5": `* Positive Plain:  連用形 + てしまう *
+* Positive Polite: 連用形 + てしまいます *
+* Negative Plain:  連用形 + てしまわない *
+* Negative Polite: 連用形 + てしまいません *`,
+		},
+	},
+	{
+		Form: Conjectural,
+		Exec: (*Word).ToConjectural,
+		Name: "Conjectural",
+		Rule: map[string]string{
+			"v1": `* Positive Plain:  連体形 + だろう
+* Positive Polite: 連体形 + でしょう
+* Negative Plain:  未然形 + ないだろう
+* Negative Polite: 未然形 + ないでしょう`,
+			"v5": `* Positive Plain:  連体形 + だろう
+* Positive Polite: 連体形 + でしょう
+* Negative Plain:  未然形 + ないだろう
+* Negative Polite: 未然形 + ないでしょう`,
+		},
+	},
+	{
+		Form: Alternative,
+		Exec: (*Word).ToAlternative,
+		Name: "Alternative",
+		Rule: map[string]string{
+			"v1": `* Positive Plain:  連用形 + たり
+* Positive Polite: 連用形 + ましたり
+* Negative Plain:  未然形 + なかったり
+* Negative Polite: 連用形 + ませんでしたり`,
+			"v5": `* Positive Plain:  連用形 + たり *
+* Positive Polite: 連用形 + ましたり
+* Negative Plain:  未然形 + なかったり
+* Negative Polite: 連用形 + ませんでしたり`,
+		},
+	},
+	{
+		Form: Imperative,
+		Exec: (*Word).ToImperative,
+		Name: "Imperative",
+		Rule: map[string]string{
+			"v1": `* Positive Plain:  命令形 + ろ
+* Positive Polite: 連用形 + なさい
+* Negative Plain:  連体形 + な
+* Negative Polite: 連用形 + なさるな`,
+			"v5": `* Positive Plain:  命令形
+* Positive Polite: 連用形 + なさい
+* Negative Plain:  連体形 + な
+* Negative Polite: 連用形 + なさるな`,
+		},
+	},
+	{
+		Form: Zu,
+		Exec: (*Word).ToZu,
+		Name: "Zu Form",
+		Rule: map[string]string{
+			"v1": `* Negative Plain:  未然形 + ず
+  する becomes せず`,
+			"v5": `* Negative Plain:  未然形 + ず`,
+		},
+	},
+	{
+		Form: Zuni,
+		Exec: (*Word).ToZuni,
+		Name: "Zuni Form",
+		Rule: map[string]string{
+			"v1": `* Negative Plain:  未然形 + ずに
+  する becomes せずに`,
+			"v5": `* Negative Plain:  未然形 + ずに`,
+		},
+	},
+}
+
+// conjunction ------------
+func (w *Word) ToPresent(positive bool, formal bool) (string, string) {
+	var kana, kanji, ending string
+
+	if positive {
+		if formal {
+			kana, kanji = w.ToMasuStem()
+			ending = "ます"
+		} else {
+			return w.ToRentaikei()
+		}
+	} else {
+		if formal {
+			kana, kanji = w.ToMasuStem()
+			ending = "ません"
+		} else {
+			kana, kanji = w.ToNegativeStem()
+			ending = "ない"
+		}
+	}
+
+	return w.attach(kana, kanji, ending)
+}
+
+func (w *Word) ToPast(positive bool, formal bool) (string, string) {
+	var kana, kanji, ending string
+
+	if positive {
+		if formal {
+			kana, kanji = w.ToMasuStem()
+			ending = "ました"
+		} else {
+			var voiced bool
+			kana, kanji, voiced = w.ToOnbinkei()
+			ending = voice("た", voiced)
+		}
+	} else {
+		if formal {
+			kana, kanji = w.ToMasuStem()
+			ending = "ませんでした"
+		} else {
+			kana, kanji = w.ToNegativeStem()
+			ending = "なかった"
+		}
+	}
+
+	return w.attach(kana, kanji, ending)
+}
+
+func (w *Word) ToTeForm(positive bool, formal bool) (string, string) {
+	var kana, kanji, ending string
+
+	if positive {
+		if formal {
+			kana, kanji = w.ToMasuStem()
+			ending = "まして"
+		} else {
+			var voiced bool
+			kana, kanji, voiced = w.ToOnbinkei()
+			ending = voice("て", voiced)
+		}
+	} else {
+		if formal {
+			kana, kanji = w.ToMasuStem()
+			ending = "ませんで"
+		} else {
+			kana, kanji = w.ToNegativeStem()
+			ending = "ないで"
+		}
+	}
+
+	return w.attach(kana, kanji, ending)
+}
+
+func (w *Word) ToConditional(positive bool, formal bool) (string, string) {
+	var kana, kanji, ending string
+
+	if positive {
+		if formal {
+			kana, kanji = w.ToMasuStem()
+			ending = "ましたら"
+		} else {
+			var voiced bool
+			kana, kanji, voiced = w.ToOnbinkei()
+			ending = voice("たら", voiced)
+		}
+	} else {
+		if formal {
+			kana, kanji = w.ToMasuStem()
+			ending = "ませんでしたら"
+		} else {
+			kana, kanji = w.ToNegativeStem()
+			ending = "なかったら"
+		}
+	}
+
+	return w.attach(kana, kanji, ending)
+}
+
+func (w *Word) ToProvisional(positive bool, formal bool) (string, string) {
+	var kana, kanji, ending string
+
+	if positive {
+		if formal {
+			kana, kanji = w.ToMasuStem()
+			ending = "ますなら"
+		} else {
+			kana, kanji = w.ToIzenkei()
+			ending = "ば"
+		}
+	} else {
+		if formal {
+			kana, kanji = w.ToMasuStem()
+			ending = "ませんなら"
+		} else {
+			kana, kanji = w.ToNegativeStem()
+			ending = "なければ"
+		}
+	}
+
+	return w.attach(kana, kanji, ending)
+}
+
+func (w *Word) ToVolitional(positive bool, formal bool) (string, string) {
+	var kana, kanji, ending string
+
+	if formal {
+		kana, kanji = w.ToMasuStem()
+		if positive {
+			ending = "ましょう"
+		} else {
+			ending = "ますまい"
+		}
+	} else if positive {
+		if w.isIchidan() || w.isSuru() {
+			kana, kanji = w.ToMizenkei()
+			ending = "よう"
+		} else {
+			// 書く: 書こう
+			kana, kanji = w.ToStem()
+			dict, _ := w.ToRentaikei()
+			o := changeVovelSound(dict[len(kana):], "お")
+			if kanji != "" {
+				kanji += o
+			}
+			kana += o
+			w.noteBase("未然形", kana)
+			ending = "う"
+		}
+	} else {
+		kana, kanji = w.ToRentaikei()
+		ending = "まい"
+	}
+
+	return w.attach(kana, kanji, ending)
+}
+
+func (w *Word) ToPotential(positive bool, formal bool) (string, string) {
+	return w.Potential().ToPresent(positive, formal)
+}
+
+func (w *Word) ToPassive(positive bool, formal bool) (string, string) {
+	return w.Passive().ToPresent(positive, formal)
+}
+
+func (w *Word) ToCausative(positive bool, formal bool) (string, string) {
+	return w.Causative().ToPresent(positive, formal)
+}
+
+func (w *Word) ToCausativePassive(positive bool, formal bool) (string, string) {
+	return w.Causative().Passive().ToPresent(positive, formal)
+}
+
+func (w *Word) ToDesiderative(positive bool, formal bool) (string, string) {
+	return w.Desiderative().ToAdjPresent(positive, formal)
+}
+
+func (w *Word) ToProgressive(positive bool, formal bool) (string, string) {
+	return w.Progressive().ToPresent(positive, formal)
+}
+
+func (w *Word) ToTeShimau(positive bool, formal bool) (string, string) {
+	return w.TeShimau().ToPresent(positive, formal)
+}
+
+func (w *Word) ToConjectural(positive bool, formal bool) (string, string) {
+	var kana, kanji, ending string
+
+	if positive {
+		kana, kanji = w.ToRentaikei()
+		if formal {
+			ending = "でしょう"
+		} else {
+			ending = "だろう"
+		}
+	} else {
+		kana, kanji = w.ToNegativeStem()
+		if formal {
+			ending = "ないでしょう"
+		} else {
+			ending = "ないだろう"
+		}
+	}
+
+	return w.attach(kana, kanji, ending)
+}
+
+func (w *Word) ToAlternative(positive bool, formal bool) (string, string) {
+	var ending string
+	kana, kanji := w.ToMasuStem()
+
+	if positive {
+		if formal {
+			ending = "ましたり"
+		} else {
+			var voiced bool
+			kana, kanji, voiced = w.ToOnbinkei()
+			ending = voice("たり", voiced)
+		}
+	} else {
+		if formal {
+			ending = "ませんでしたり"
+		} else {
+			kana, kanji = w.ToNegativeStem()
+			ending = "なかったり"
+		}
+	}
+
+	return w.attach(kana, kanji, ending)
+}
+
+func (w *Word) ToImperative(positive bool, formal bool) (string, string) {
+	var kana, kanji, ending string
+
+	if positive {
+		if formal {
+			kana, kanji = w.ToMasuStem()
+			ending = "なさい"
+		} else {
+			kana, kanji = w.ToMeireikei()
+			switch w.Class() {
+			case "v1", "v5uru", "vs", "vs-i", "vs-s", "vz":
+				ending = "ろ"
+			}
+		}
+	} else {
+		if formal {
+			kana, kanji = w.ToMasuStem()
+			ending = "なさるな"
+		} else {
+			kana, kanji = w.ToRentaikei()
+			ending = "な"
+		}
+	}
+
+	return w.attach(kana, kanji, ending)
+}
+
+// ToZu returns the literary negative 書かず. It only exists as a plain
+// negative.
+func (w *Word) ToZu(positive bool, formal bool) (string, string) {
+	if positive || formal {
+		return "", ""
+	}
+
+	kana, kanji := w.ToMizenkei()
+	if w.isSuru() {
+		// する: せず
+		kana, kanji = swapLast(kana, "し", "せ"), swapLast(kanji, "し", "せ")
+		w.noteBase("未然形", kana)
+	}
+
+	return w.attach(kana, kanji, "ず")
+}
+
+// ToZuni returns 書かずに, "without writing". It only exists as a plain
+// negative.
+func (w *Word) ToZuni(positive bool, formal bool) (string, string) {
+	kana, kanji := w.ToZu(positive, formal)
+	if kana == "" {
+		return "", ""
+	}
+
+	return w.attach(kana, kanji, "に")
+}
+
+// Inflection bases -------------
+
+// Class returns the JMdict conjugation class of the word, e.g. "v1",
+// "v5k-s", "vs" or "adj-i". An empty string is returned for words that
+// don't conjugate.
+func (w *Word) Class() string {
+	for _, g := range w.Gloss {
+		for _, p := range g.Pos {
+			switch {
+			case strings.HasPrefix(p, "v1"), strings.HasPrefix(p, "v5"),
+				strings.HasPrefix(p, "vs"), p == "vk", p == "vz",
+				p == "adj-i", p == "adj-ix", p == "adj-na":
+				return p
+			}
+		}
+	}
+	return ""
+}
+
+// isIchidan reports whether the word attaches its endings directly to
+// the stem the way ichidan verbs do.
+func (w *Word) isIchidan() bool {
+	switch w.Class() {
+	case "v1", "v1-s", "v5uru", "vk", "vz":
+		return true
+	}
+	return false
+}
+
+// isSuru reports whether the word is する, a noun taking する or a
+// compound like 察する that conjugates the same way.
+func (w *Word) isSuru() bool {
+	c := w.Class()
+	return c == "vs" || c == "vs-i" || c == "vs-s"
+}
+
+// ToStem returns the word without its last kana. The kanji spelling is
+// left out if it doesn't end in that kana, as its inflected forms can't be
+// written then.
+func (w *Word) ToStem() (string, string) {
+	kana, kanji := w.ToRentaikei()
+	last, size := utf8.DecodeLastRuneInString(kana)
+
+	if strings.HasSuffix(kanji, string(last)) {
+		kanji = kanji[:len(kanji)-size]
+	} else {
+		kanji = ""
+	}
+
+	return kana[:len(kana)-size], kanji
+}
+
+func (w *Word) ToMizenkei() (string, string) {
+	kana, kanji := w.mizenkei()
+	w.noteBase("未然形", kana)
+	return kana, kanji
+}
+
+func (w *Word) mizenkei() (string, string) {
+	stem, kstem := w.ToStem()
+
+	switch w.Class() {
+	case "v1", "v1-s":
+		return stem, kstem
+	case "v5uru":
+		// 得る (うる) conjugates like the ichidan verb える
+		return swapLast(stem, "う", "え"), kstem
+	case "vk":
+		return swapLast(stem, "く", "こ"), swapLast(kstem, "く", "こ")
+	case "vs", "vs-i", "vs-s":
+		return swapLast(stem, "す", "し"), swapLast(kstem, "す", "し")
+	case "vz":
+		return swapLast(stem, "ず", "じ"), swapLast(kstem, "ず", "じ")
+	default:
+		kana, _ := w.ToRentaikei()
+		ending := changeVovelSound(kana[len(stem):], "あ")
+
+		if kstem != "" {
+			return stem + ending, kstem + ending
+		} else {
+			return stem + ending, kstem
+		}
+	}
+}
+
+// ToNegativeStem returns the base ない is attached to. This is the
+// 未然形 for every verb but ある, whose negative is just ない.
+func (w *Word) ToNegativeStem() (string, string) {
+	if w.Class() == "v5r-i" {
+		w.trace.note("ない replaces ある", "")
+		return "", ""
+	}
+	return w.ToMizenkei()
+}
+
+// ToVoiceStem returns the base the passive and causative endings are
+// attached to, together with the infix put in front of the ending. The
+// infix is only used after ichidan-like stems, e.g. ら for the passive
+// (食べ + られる) or さ for the causative (食べ + させる), and empty else.
+func (w *Word) ToVoiceStem(infix string) (string, string, string) {
+	stem, kstem := w.ToMizenkei()
+
+	if w.isSuru() {
+		// the kanji spelling can't be inflected if し isn't written out,
+		// as for 為る: 為 is read し, but can't stand for さ
+		if !strings.HasSuffix(kstem, "し") {
+			kstem = ""
+		}
+		stem, kstem = swapLast(stem, "し", "さ"), swapLast(kstem, "し", "さ")
+	}
+	if !w.isIchidan() {
+		infix = ""
+	}
+
+	w.noteBase("未然形", stem)
+	return stem, kstem, infix
+}
+
+func (w *Word) ToRenyoukei() (string, string) {
+	kana, kanji := w.renyoukei()
+	w.noteBase("連用形", kana)
+	return kana, kanji
+}
+
+// ToMasuStem returns the base ます is attached to. This is the 連用形 for
+// every verb but the honorific ones like なさる or くださる, which drop
+// their r: なさいます, but なさりたい.
+func (w *Word) ToMasuStem() (string, string) {
+	if w.Class() != "v5aru" {
+		return w.ToRenyoukei()
+	}
+
+	kana, kanji := w.masuStem()
+	w.noteBase("連用形", kana)
+	return kana, kanji
+}
+
+func (w *Word) masuStem() (string, string) {
+	stem, kstem := w.ToStem()
+	if kstem != "" {
+		return stem + "い", kstem + "い"
+	}
+	return stem + "い", kstem
+}
+
+func (w *Word) renyoukei() (string, string) {
+	stem, kstem := w.ToStem()
+
+	switch w.Class() {
+	case "v1", "v1-s", "v5uru", "vs", "vs-i", "vs-s", "vz":
+		return w.ToMizenkei()
+	case "vk":
+		return swapLast(stem, "く", "き"), swapLast(kstem, "く", "き")
+	default:
+		kana, _ := w.ToRentaikei()
+		ending := changeVovelSound(kana[len(stem):], "い")
+
+		if kstem != "" {
+			return stem + ending, kstem + ending
+		} else {
+			return stem + ending, kstem
+		}
+	}
+}
+
+// ToOnbinkei returns the euphonic base the past and te-form endings are
+// attached to (書い, 読ん, 買っ) and whether those endings become voiced
+// (読んだ instead of 読んた).
+func (w *Word) ToOnbinkei() (string, string, bool) {
+	var ending string
+	voiced := false
+	stem, kstem := w.ToStem()
+	kana, _ := w.ToRentaikei()
+
+	if w.isIchidan() || w.isSuru() {
+		kana, kanji := w.ToRenyoukei()
+		return kana, kanji, false
+	}
+
+	switch w.Class() {
+	case "v5k-s":
+		// 行く is the only く verb without イ音便: 行った
+		ending = "っ"
+	case "v5u-s":
+		// 問う and 請う keep their う: 問うた
+		ending = "う"
+	default:
+		switch kana[len(stem):] {
+		case "く":
+			ending = "い"
+		case "ぐ":
+			ending = "い"
+			voiced = true
+		case "ぬ", "ぶ", "む":
+			ending = "ん"
+			voiced = true
+		case "う", "つ", "る":
+			ending = "っ"
+		default:
+			kana, kanji := w.ToRenyoukei()
+			return kana, kanji, false
+		}
+	}
+
+	if kstem != "" {
+		kstem += ending
+	}
+	stem += ending
+
+	w.noteBase("音便", stem)
+	return stem, kstem, voiced
+}
+
+// ToRentaikei returns the dictionary form. Nouns taking する get it
+// appended.
+func (w *Word) ToRentaikei() (string, string) {
+	kana, kanji := w.Kana, w.Kanji[0]
+
+	if w.Class() == "vs" && !strings.HasSuffix(kana, "する") {
+		kana += "する"
+		if kanji != "" {
+			kanji += "する"
+		}
+	}
+	return kana, kanji
+}
+
+func (w *Word) ToIzenkei() (string, string) {
+	stem, kstem := w.ToStem()
+	kana, _ := w.ToRentaikei()
+	ending := changeVovelSound(kana[len(stem):], "え")
+
+	if kstem != "" {
+		kstem += ending
+	}
+	stem += ending
+
+	w.noteBase("已然形", stem)
+	return stem, kstem
+}
+
+func (w *Word) ToMeireikei() (string, string) {
+	kana, kanji := w.meireikei()
+	w.noteBase("命令形", kana)
+	return kana, kanji
+}
+
+func (w *Word) meireikei() (string, string) {
+	stem, kstem := w.ToStem()
+
+	switch w.Class() {
+	case "v1", "v5uru", "vs", "vs-i", "vs-s", "vz":
+		return w.ToMizenkei()
+	case "v1-s":
+		// くれる: くれ instead of くれろ
+		return stem, kstem
+	case "vk":
+		kana, kanji := w.ToMizenkei()
+		if kanji != "" {
+			return kana + "い", kanji + "い"
+		} else {
+			return kana + "い", kanji
+		}
+	case "v5aru":
+		// なさる: なさい like its ます base
+		return w.masuStem()
+	default:
+		return w.ToIzenkei()
+	}
+}
+
+// helper functions
+
+// swapLast replaces the trailing old of s by new. s is returned unchanged
+// if it doesn't end in old, e.g. for a kanji spelling without okurigana.
+func swapLast(s string, old string, new string) string {
+	if strings.HasSuffix(s, old) {
+		return s[:len(s)-len(old)] + new
+	}
+	return s
+}
+
+// voice turns the leading た or て of ending into だ or で if voiced is set.
+func voice(ending string, voiced bool) string {
+	if !voiced {
+		return ending
+	}
+	if strings.HasPrefix(ending, "た") {
+		return "だ" + ending[len("た"):]
+	}
+	return "で" + ending[len("て"):]
+}
+
+func changeVovelSound(vovel string, sound string) string {
+	//lastVovel, _ := utf8.DecodeLastRuneInString(vovel)
+	lastVovel := vovel
+	if sound == "あ" {
+		switch lastVovel {
+		case "う":
+			// WARNING: could be very wrong, not quiet sure. Maybe move it to the construction of the past tense
+			return "わ"
+		case "る":
+			return "ら"
+		case "す":
+			return "さ"
+		case "く":
+			return "か"
+		case "ぐ":
+			return "が"
+		case "む":
+			return "ま"
+		case "ぶ":
+			return "ば"
+		case "ぬ":
+			return "な"
+		case "つ":
+			return "た"
+		}
+	} else if sound == "い" {
+		switch lastVovel {
+		case "う":
+			return "い"
+		case "る":
+			return "り"
+		case "す":
+			return "し"
+		case "く":
+			return "き"
+		case "ぐ":
+			return "ぎ"
+		case "む":
+			return "み"
+		case "ぶ":
+			return "び"
+		case "ぬ":
+			return "に"
+		case "つ":
+			return "ち"
+		}
+	} else if sound == "お" {
+		switch lastVovel {
+		case "う":
+			return "お"
+		case "る":
+			return "ろ"
+		case "す":
+			return "そ"
+		case "く":
+			return "こ"
+		case "ぐ":
+			return "ご"
+		case "む":
+			return "も"
+		case "ぶ":
+			return "ぼ"
+		case "ぬ":
+			return "の"
+		case "つ":
+			return "と"
+		}
+	} else if sound == "え" {
+		switch lastVovel {
+		case "う":
+			return "え"
+		case "る":
+			return "れ"
+		case "す":
+			return "せ"
+		case "く":
+			return "け"
+		case "ぐ":
+			return "げ"
+		case "む":
+			return "め"
+		case "ぶ":
+			return "べ"
+		case "ぬ":
+			return "ね"
+		case "つ":
+			return "て"
+		}
+	}
+	return ""
+}
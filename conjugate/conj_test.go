@@ -0,0 +1,202 @@
+package conjugate
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files from the current output")
+
+var goldenFile = filepath.Join("testdata", "conjugations.golden")
+
+const goldenHeader = "# class\tword\tform\tpositive plain\tpositive polite\tnegative plain\tnegative polite"
+
+// goldenEntry is a word of the golden file together with its expected
+// forms by conjugation name.
+type goldenEntry struct {
+	class string
+	word  string
+	forms map[string][]string
+	order []string
+}
+
+// splitCell splits a golden file cell like 書く(かく) into its kana and
+// kanji spelling. Kana-only cells have no parentheses.
+func splitCell(cell string) (kana string, kanji string) {
+	if i := strings.Index(cell, "("); i >= 0 && strings.HasSuffix(cell, ")") {
+		return cell[i+1 : len(cell)-1], cell[:i]
+	}
+	return cell, ""
+}
+
+func joinCell(kana string, kanji string) string {
+	if kanji == "" {
+		return kana
+	}
+	return kanji + "(" + kana + ")"
+}
+
+// fixture returns an in-memory word of the given class for a golden file
+// word cell.
+func fixture(class string, cell string) *Word {
+	kana, kanji := splitCell(cell)
+	return &Word{
+		Kana:  kana,
+		Kanji: []string{kanji},
+		Gloss: []*Gloss{{Pos: []string{class}}},
+	}
+}
+
+// golden file column names of the forms returned by conjugateAll
+var columns = []string{"positive plain", "positive polite", "negative plain", "negative polite"}
+
+// conjugateAll returns the forms of c in golden file order: positive
+// plain, positive polite, negative plain and negative polite.
+func conjugateAll(w *Word, c Conjugation) []string {
+	var cells []string
+	for _, positive := range []bool{true, false} {
+		for _, polite := range []bool{false, true} {
+			cells = append(cells, joinCell(c.Exec(w, positive, polite)))
+		}
+	}
+	return cells
+}
+
+func readGolden(t *testing.T) []*goldenEntry {
+	f, err := os.Open(goldenFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	var entries []*goldenEntry
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Split(line, "\t")
+		if len(fields) != 7 {
+			t.Fatalf("%s:%d: expected 7 fields, got %d", goldenFile, n, len(fields))
+		}
+
+		var e *goldenEntry
+		if len(entries) > 0 {
+			e = entries[len(entries)-1]
+		}
+		if e == nil || e.class != fields[0] || e.word != fields[1] {
+			e = &goldenEntry{class: fields[0], word: fields[1], forms: make(map[string][]string)}
+			entries = append(entries, e)
+		}
+		if _, ok := e.forms[fields[2]]; ok {
+			t.Fatalf("%s:%d: duplicate form %s of %s", goldenFile, n, fields[2], e.word)
+		}
+		e.forms[fields[2]] = fields[3:]
+		e.order = append(e.order, fields[2])
+	}
+	if err := scanner.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	return entries
+}
+
+func writeGolden(t *testing.T, entries []*goldenEntry) {
+	var b strings.Builder
+	fmt.Fprintln(&b, goldenHeader)
+
+	for _, e := range entries {
+		w := fixture(e.class, e.word)
+		for _, c := range w.Conjugations() {
+			fmt.Fprintf(&b, "%s\t%s\t%s\t%s\n", e.class, e.word, c.Name, strings.Join(conjugateAll(w, c), "\t"))
+		}
+	}
+
+	if err := os.WriteFile(goldenFile, []byte(b.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestConjugations checks every conjugation of every word listed in the
+// golden file. Run with -update after adding words or conjugations and
+// review the diff of testdata/conjugations.golden.
+func TestConjugations(t *testing.T) {
+	entries := readGolden(t)
+	if *update {
+		writeGolden(t, entries)
+		return
+	}
+
+	for _, e := range entries {
+		w := fixture(e.class, e.word)
+		conjs := w.Conjugations()
+		if conjs == nil {
+			t.Errorf("%s (%s): class doesn't conjugate", e.word, e.class)
+			continue
+		}
+
+		known := make(map[string]bool)
+		for _, c := range conjs {
+			known[c.Name] = true
+
+			want, ok := e.forms[c.Name]
+			if !ok {
+				t.Errorf("%s (%s): no golden forms for %s", e.word, e.class, c.Name)
+				continue
+			}
+
+			got := conjugateAll(w, c)
+			for i := range got {
+				if got[i] != want[i] {
+					t.Errorf("%s (%s) %s %s: got %s, want %s", e.word, e.class, c.Name, columns[i], got[i], want[i])
+				}
+			}
+		}
+
+		for _, name := range e.order {
+			if !known[name] {
+				t.Errorf("%s (%s): golden form %s is not a conjugation of the word", e.word, e.class, name)
+			}
+		}
+	}
+}
+
+// TestGoldenClasses makes sure the golden file covers every conjugation
+// class the engine handles.
+func TestGoldenClasses(t *testing.T) {
+	classes := []string{
+		"v1", "v1-s", "v5aru", "v5b", "v5g", "v5k", "v5k-s", "v5m", "v5n", "v5r", "v5r-i",
+		"v5s", "v5t", "v5u", "v5u-s", "v5uru", "vk", "vs", "vs-c", "vs-i", "vs-s", "vz",
+		"adj-i", "adj-ix", "adj-na",
+	}
+
+	covered := make(map[string]bool)
+	for _, e := range readGolden(t) {
+		covered[e.class] = true
+	}
+
+	for _, c := range classes {
+		if !covered[c] {
+			t.Errorf("no golden words of class %s", c)
+		}
+	}
+}
+
+func TestConjugateUnknownForm(t *testing.T) {
+	w := fixture("v5k", "書く(かく)")
+	if _, _, err := Conjugate(w, Adverbial, Positive, Plain); err != ErrUnknownForm {
+		t.Errorf("got %v, want ErrUnknownForm", err)
+	}
+
+	kana, kanji, err := Conjugate(w, Past, Negative, Polite)
+	if err != nil || kana != "かきませんでした" || kanji != "書きませんでした" {
+		t.Errorf("got %s, %s, %v", kana, kanji, err)
+	}
+}
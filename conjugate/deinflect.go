@@ -0,0 +1,280 @@
+package conjugate
+
+import (
+	"strings"
+)
+
+// word types a deinflection rule applies to and produces
+const (
+	typeV1    = 1 << iota // ichidan verbs and forms ending like them, e.g. 食べさせる
+	typeV5                // godan verbs
+	typeVK                // 来る
+	typeVS                // する and nouns taking it
+	typeAdjI              // i-adjectives and forms ending like them, e.g. 食べない
+	typeMasu              // forms ending in ます
+	typeMasen             // forms ending in ません
+	typeFinal             // forms nothing else attaches to, e.g. 食べた
+
+	typeDict = typeV1 | typeV5 | typeVK | typeVS | typeAdjI
+	typeAll  = typeDict | typeMasu | typeMasen | typeFinal
+)
+
+// deinflectRule undoes a single inflection: a word of type in ending in
+// from is turned into a word of type out ending in to.
+type deinflectRule struct {
+	from   string
+	to     string
+	in     int
+	out    int
+	reason string
+}
+
+// Deinflection is a possible dictionary form of an inflected word. Chain
+// lists the inflections leading from Word to the inflected form, innermost
+// first, e.g. causative, passive/potential, negative, past for
+// 食べさせられなかった. Stacked forms like the causative passive are
+// undone one auxiliary at a time.
+type Deinflection struct {
+	Word  string
+	Chain []string
+	typ   int
+}
+
+var deinflectRules = buildDeinflectRules()
+
+func buildDeinflectRules() []deinflectRule {
+	rules := []deinflectRule{
+		// polite endings, whatever they are attached to
+		{"ました", "ます", typeFinal, typeMasu, "past"},
+		{"まして", "ます", typeFinal, typeMasu, "te-form"},
+		{"ましたら", "ます", typeFinal, typeMasu, "conditional"},
+		{"ましたり", "ます", typeFinal, typeMasu, "alternative"},
+		{"ますなら", "ます", typeFinal, typeMasu, "provisional"},
+		{"ません", "ます", typeMasen, typeMasu, "negative"},
+		{"ませんでした", "ません", typeFinal, typeMasen, "past"},
+		{"ませんで", "ません", typeFinal, typeMasen, "te-form"},
+		{"ませんでしたら", "ません", typeFinal, typeMasen, "conditional"},
+		{"ませんでしたり", "ません", typeFinal, typeMasen, "alternative"},
+		{"ませんなら", "ません", typeFinal, typeMasen, "provisional"},
+		{"ましょう", "ます", typeFinal, typeMasu, "volitional"},
+		{"ますまい", "ます", typeFinal, typeMasu, "negative volitional"},
+
+		{"だろう", "", typeFinal, typeDict, "conjectural"},
+		{"でしょう", "", typeFinal, typeDict, "conjectural"},
+		{"な", "", typeFinal, typeV1 | typeV5 | typeVK | typeVS, "negative imperative"},
+		{"ないで", "ない", typeFinal, typeAdjI, "te-form"},
+		{"まい", "", typeFinal, typeV1 | typeV5 | typeVK | typeVS, "negative volitional"},
+
+		// auxiliaries attached to the te-form
+		{"ている", "て", typeV1, typeFinal, "progressive"},
+		{"でいる", "で", typeV1, typeFinal, "progressive"},
+		{"てる", "て", typeV1, typeFinal, "progressive"},
+		{"でる", "で", typeV1, typeFinal, "progressive"},
+		{"てしまう", "て", typeV5, typeFinal, "shimau"},
+		{"でしまう", "で", typeV5, typeFinal, "shimau"},
+		{"ちゃう", "て", typeV5, typeFinal, "shimau"},
+		{"じゃう", "で", typeV5, typeFinal, "shimau"},
+
+		// 一段
+		{"ない", "る", typeAdjI, typeV1, "negative"},
+		{"ます", "る", typeMasu, typeV1, "polite"},
+		{"た", "る", typeFinal, typeV1, "past"},
+		{"て", "る", typeFinal, typeV1, "te-form"},
+		{"たら", "る", typeFinal, typeV1, "conditional"},
+		{"たり", "る", typeFinal, typeV1, "alternative"},
+		{"れば", "る", typeFinal, typeV1, "provisional"},
+		{"ろ", "る", typeFinal, typeV1, "imperative"},
+		{"なさい", "る", typeFinal, typeV1, "polite imperative"},
+		{"なさるな", "る", typeFinal, typeV1, "polite negative imperative"},
+		{"られる", "る", typeV1, typeV1, "passive/potential"},
+		{"させる", "る", typeV1, typeV1, "causative"},
+		{"よう", "る", typeFinal, typeV1, "volitional"},
+		{"たい", "る", typeAdjI, typeV1, "desiderative"},
+		{"ず", "る", typeFinal, typeV1, "zu"},
+		{"ずに", "る", typeFinal, typeV1, "zuni"},
+		{"くれ", "くれる", typeFinal, typeV1, "imperative"},
+
+		// spelling variants, they don't add to the chain
+		{"じる", "ずる", typeV1, typeV1, ""},
+		{"よい", "いい", typeAdjI, typeAdjI, ""},
+
+		// 五段 exceptions
+		{"うた", "う", typeFinal, typeV5, "past"},
+		{"うて", "う", typeFinal, typeV5, "te-form"},
+		{"うたら", "う", typeFinal, typeV5, "conditional"},
+		{"うたり", "う", typeFinal, typeV5, "alternative"},
+		{"います", "る", typeMasu, typeV5, "polite"},
+		{"い", "る", typeFinal, typeV5, "imperative"},
+		{"いなさい", "る", typeFinal, typeV5, "polite imperative"},
+		{"いなさるな", "る", typeFinal, typeV5, "polite negative imperative"},
+		{"ない", "ある", typeAdjI, typeV5, "negative"},
+		{"ありえる", "ある", typeV1, typeV5, "potential"},
+
+		// する
+		{"しない", "する", typeAdjI, typeVS, "negative"},
+		{"します", "する", typeMasu, typeVS, "polite"},
+		{"した", "する", typeFinal, typeVS, "past"},
+		{"して", "する", typeFinal, typeVS, "te-form"},
+		{"したら", "する", typeFinal, typeVS, "conditional"},
+		{"したり", "する", typeFinal, typeVS, "alternative"},
+		{"すれば", "する", typeFinal, typeVS, "provisional"},
+		{"しろ", "する", typeFinal, typeVS, "imperative"},
+		{"しなさい", "する", typeFinal, typeVS, "polite imperative"},
+		{"しなさるな", "する", typeFinal, typeVS, "polite negative imperative"},
+		{"される", "する", typeV1, typeVS, "passive"},
+		{"できる", "する", typeV1, typeVS, "potential"},
+		{"させる", "する", typeV1, typeVS, "causative"},
+		{"せる", "する", typeV1, typeVS, "potential"},
+		{"しよう", "する", typeFinal, typeVS, "volitional"},
+		{"したい", "する", typeAdjI, typeVS, "desiderative"},
+		{"せず", "する", typeFinal, typeVS, "zu"},
+		{"せずに", "する", typeFinal, typeVS, "zuni"},
+
+		// i-adjectives
+		{"くない", "い", typeAdjI, typeAdjI, "negative"},
+		{"かった", "い", typeFinal, typeAdjI, "past"},
+		{"くて", "い", typeFinal, typeAdjI, "te-form"},
+		{"かったら", "い", typeFinal, typeAdjI, "conditional"},
+		{"かったり", "い", typeFinal, typeAdjI, "alternative"},
+		{"ければ", "い", typeFinal, typeAdjI, "provisional"},
+		{"く", "い", typeFinal, typeAdjI, "adverbial"},
+		{"くあります", "い", typeMasu, typeAdjI, "polite"},
+		{"いです", "い", typeFinal, typeAdjI, "polite"},
+		{"かったです", "かった", typeFinal, typeFinal, "polite"},
+	}
+
+	// 行く, in kanji and both readings
+	for _, i := range []string{"行", "い", "ゆ"} {
+		rules = append(rules,
+			deinflectRule{i + "った", i + "く", typeFinal, typeV5, "past"},
+			deinflectRule{i + "って", i + "く", typeFinal, typeV5, "te-form"},
+			deinflectRule{i + "ったら", i + "く", typeFinal, typeV5, "conditional"},
+			deinflectRule{i + "ったり", i + "く", typeFinal, typeV5, "alternative"},
+		)
+	}
+
+	// 来る, in kana and kanji
+	for _, k := range []struct{ ku, ko, ki string }{{"く", "こ", "き"}, {"来", "来", "来"}} {
+		rules = append(rules,
+			deinflectRule{k.ko + "ない", k.ku + "る", typeAdjI, typeVK, "negative"},
+			deinflectRule{k.ki + "ます", k.ku + "る", typeMasu, typeVK, "polite"},
+			deinflectRule{k.ki + "た", k.ku + "る", typeFinal, typeVK, "past"},
+			deinflectRule{k.ki + "て", k.ku + "る", typeFinal, typeVK, "te-form"},
+			deinflectRule{k.ki + "たら", k.ku + "る", typeFinal, typeVK, "conditional"},
+			deinflectRule{k.ki + "たり", k.ku + "る", typeFinal, typeVK, "alternative"},
+			deinflectRule{k.ku + "れば", k.ku + "る", typeFinal, typeVK, "provisional"},
+			deinflectRule{k.ko + "い", k.ku + "る", typeFinal, typeVK, "imperative"},
+			deinflectRule{k.ki + "なさい", k.ku + "る", typeFinal, typeVK, "polite imperative"},
+			deinflectRule{k.ki + "なさるな", k.ku + "る", typeFinal, typeVK, "polite negative imperative"},
+			deinflectRule{k.ko + "られる", k.ku + "る", typeV1, typeVK, "passive/potential"},
+			deinflectRule{k.ko + "させる", k.ku + "る", typeV1, typeVK, "causative"},
+			deinflectRule{k.ko + "よう", k.ku + "る", typeFinal, typeVK, "volitional"},
+			deinflectRule{k.ki + "たい", k.ku + "る", typeAdjI, typeVK, "desiderative"},
+			deinflectRule{k.ko + "ず", k.ku + "る", typeFinal, typeVK, "zu"},
+			deinflectRule{k.ko + "ずに", k.ku + "る", typeFinal, typeVK, "zuni"},
+		)
+	}
+
+	// 五段, generated for every ending
+	onbin := map[string]string{
+		"う": "った", "つ": "った", "る": "った",
+		"く": "いた", "ぐ": "いだ", "す": "した",
+		"ぬ": "んだ", "ぶ": "んだ", "む": "んだ",
+	}
+	for _, u := range []string{"う", "く", "ぐ", "す", "つ", "ぬ", "ぶ", "む", "る"} {
+		a := changeVovelSound(u, "あ")
+		i := changeVovelSound(u, "い")
+		e := changeVovelSound(u, "え")
+		o := changeVovelSound(u, "お")
+		past := onbin[u]
+		te := voice("て", strings.HasSuffix(past, "だ"))
+		stem := strings.TrimSuffix(strings.TrimSuffix(past, "た"), "だ")
+
+		rules = append(rules,
+			deinflectRule{a + "ない", u, typeAdjI, typeV5, "negative"},
+			deinflectRule{i + "ます", u, typeMasu, typeV5, "polite"},
+			deinflectRule{past, u, typeFinal, typeV5, "past"},
+			deinflectRule{stem + te, u, typeFinal, typeV5, "te-form"},
+			deinflectRule{past + "ら", u, typeFinal, typeV5, "conditional"},
+			deinflectRule{past + "り", u, typeFinal, typeV5, "alternative"},
+			deinflectRule{e + "ば", u, typeFinal, typeV5, "provisional"},
+			deinflectRule{e, u, typeFinal, typeV5, "imperative"},
+			deinflectRule{i + "なさい", u, typeFinal, typeV5, "polite imperative"},
+			deinflectRule{i + "なさるな", u, typeFinal, typeV5, "polite negative imperative"},
+			deinflectRule{e + "る", u, typeV1, typeV5, "potential"},
+			deinflectRule{a + "れる", u, typeV1, typeV5, "passive"},
+			deinflectRule{a + "せる", u, typeV1, typeV5, "causative"},
+			deinflectRule{o + "う", u, typeFinal, typeV5, "volitional"},
+			deinflectRule{i + "たい", u, typeAdjI, typeV5, "desiderative"},
+			deinflectRule{a + "ず", u, typeFinal, typeV5, "zu"},
+			deinflectRule{a + "ずに", u, typeFinal, typeV5, "zuni"},
+		)
+		if u != "す" {
+			// short causative passive: 書かされる
+			rules = append(rules, deinflectRule{a + "される", u, typeV1, typeV5, "causative passive"})
+		}
+	}
+
+	return rules
+}
+
+// Deinflect walks s back through the conjugation rules and returns every
+// candidate dictionary form it may be an inflection of, s itself first.
+// The candidates aren't checked against a dictionary, use Matches for
+// that.
+func Deinflect(s string) []*Deinflection {
+	results := []*Deinflection{{Word: s, typ: typeAll}}
+	seen := map[string]int{s: typeAll}
+
+	for i := 0; i < len(results); i++ {
+		d := results[i]
+
+		for _, r := range deinflectRules {
+			if d.typ&r.in == 0 || !strings.HasSuffix(d.Word, r.from) {
+				continue
+			}
+
+			word := strings.TrimSuffix(d.Word, r.from) + r.to
+			if word == "" || seen[word]&r.out == r.out {
+				continue
+			}
+			seen[word] |= r.out
+
+			chain := d.Chain
+			if r.reason != "" {
+				chain = append([]string{r.reason}, d.Chain...)
+			}
+			results = append(results, &Deinflection{Word: word, Chain: chain, typ: r.out})
+		}
+	}
+
+	return results
+}
+
+// Matches reports whether w, spelled like the deinflection's Word, is of a
+// class that can produce the inflection chain.
+func (d *Deinflection) Matches(w *Word) bool {
+	c := w.Class()
+
+	switch {
+	case c == "":
+		return len(d.Chain) == 0
+	case c == "v1", c == "v1-s", c == "vz":
+		return d.typ&typeV1 != 0
+	case strings.HasPrefix(c, "v5"), c == "vs-c":
+		return d.typ&typeV5 != 0
+	case c == "vk":
+		return d.typ&typeVK != 0
+	case strings.HasPrefix(c, "vs"):
+		return d.typ&typeVS != 0
+	case c == "adj-i", c == "adj-ix":
+		return d.typ&typeAdjI != 0
+	}
+	return len(d.Chain) == 0
+}
+
+// IsSuru reports whether the deinflection is a form of する, which might
+// belong to a noun taking する, e.g. 勉強する.
+func (d *Deinflection) IsSuru() bool {
+	return d.typ&typeVS != 0 && strings.HasSuffix(d.Word, "する")
+}
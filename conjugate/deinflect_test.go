@@ -0,0 +1,84 @@
+package conjugate
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDeinflect(t *testing.T) {
+	tests := []struct {
+		in    string
+		word  string
+		class string
+		chain string
+	}{
+		{"食べさせられなかった", "食べる", "v1", "causative,passive/potential,negative,past"},
+		{"書かされた", "書く", "v5k", "causative passive,past"},
+		{"書けます", "書く", "v5k", "potential,polite"},
+		{"行った", "行く", "v5k-s", "past"},
+		{"来なかった", "来る", "vk", "negative,past"},
+		{"こさせられる", "くる", "vk", "causative,passive/potential"},
+		{"しませんでした", "する", "vs-i", "polite,negative,past"},
+		{"なかった", "ある", "v5r-i", "negative,past"},
+		{"高くありませんでした", "高い", "adj-i", "polite,negative,past"},
+		{"よくない", "いい", "adj-ix", "negative"},
+		{"信じられる", "信ずる", "vz", "passive/potential"},
+		{"食べる", "食べる", "v1", ""},
+		{"読んでしまった", "読む", "v5m", "te-form,shimau,past"},
+		{"書いていません", "書く", "v5k", "te-form,progressive,polite,negative"},
+		{"行きたくなかった", "行く", "v5k-s", "desiderative,negative,past"},
+		{"書こう", "書く", "v5k", "volitional"},
+		{"書けない", "書く", "v5k", "potential,negative"},
+		{"食べずに", "食べる", "v1", "zuni"},
+		{"せずに", "する", "vs-i", "zuni"},
+	}
+
+	for _, tt := range tests {
+		w := &Word{Kana: tt.word, Kanji: []string{""}, Gloss: []*Gloss{{Pos: []string{tt.class}}}}
+
+		found := false
+		for _, d := range Deinflect(tt.in) {
+			if d.Word == tt.word && d.Matches(w) && strings.Join(d.Chain, ",") == tt.chain {
+				found = true
+			}
+		}
+		if !found {
+			t.Errorf("%s: no deinflection to %s (%s) via %q", tt.in, tt.word, tt.class, tt.chain)
+		}
+	}
+}
+
+// TestDeinflectGolden deinflects every kana form of the golden file back
+// to its dictionary form.
+func TestDeinflectGolden(t *testing.T) {
+	for _, e := range readGolden(t) {
+		switch e.class {
+		case "v5uru", "adj-na":
+			// 得る deinflects to the ichidan える, na-adjectives aren't handled
+			continue
+		}
+
+		w := fixture(e.class, e.word)
+		dict, _ := w.ToRentaikei()
+
+		for _, name := range e.order {
+			for i, cell := range e.forms[name] {
+				kana, _ := splitCell(cell)
+				if kana == "" {
+					continue
+				}
+
+				found := false
+				for _, d := range Deinflect(kana) {
+					if d.Word == dict && d.Matches(w) {
+						found = true
+						break
+					}
+				}
+				if !found {
+					t.Errorf("%s (%s) %s %s: %s doesn't deinflect to %s", e.word, e.class, name, columns[i], kana, dict)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package conjugate
+
+import (
+	"unicode"
+)
+
+// Segment is a part of a kanji spelling together with its reading. The
+// reading of kana segments is empty.
+type Segment struct {
+	Text    string
+	Reading string
+}
+
+// Align splits the kanji spelling into runs of kanji and kana and pairs
+// every kanji run with its part of the reading kana, e.g. 食べる and
+// たべる give 食 (た) and べる. nil is returned if the spelling doesn't fit
+// the reading.
+func Align(kanji string, kana string) []Segment {
+	var runs []run
+	for _, r := range kanji {
+		isKana := isKanaRune(r)
+		if len(runs) == 0 || runs[len(runs)-1].kana != isKana {
+			runs = append(runs, run{kana: isKana})
+		}
+		runs[len(runs)-1].text = append(runs[len(runs)-1].text, r)
+	}
+
+	segments := make([]Segment, len(runs))
+	if !align(runs, []rune(kana), segments) {
+		return nil
+	}
+	return segments
+}
+
+// run is a part of a kanji spelling made up of either kanji or kana only.
+type run struct {
+	text []rune
+	kana bool
+}
+
+// align fills in the segments of the runs by matching the kana runs
+// against the reading and giving each kanji run the shortest reading that
+// lets the rest of the runs match.
+func align(runs []run, reading []rune, segments []Segment) bool {
+	if len(runs) == 0 {
+		return len(reading) == 0
+	}
+
+	r := runs[0]
+	segments[0].Text = string(r.text)
+
+	if r.kana {
+		n := len(r.text)
+		if n > len(reading) || toHiragana(r.text) != toHiragana(reading[:n]) {
+			return false
+		}
+		return align(runs[1:], reading[n:], segments[1:])
+	}
+
+	for n := 1; n <= len(reading); n++ {
+		if align(runs[1:], reading[n:], segments[1:]) {
+			segments[0].Reading = string(reading[:n])
+			return true
+		}
+	}
+	return false
+}
+
+// Furigana writes the segments with the reading of each kanji run in
+// brackets, the syntax Anki uses: 食[た]べる. Kanji runs following kana are
+// separated from it by a space to mark where the run starts: 貸[か]し 出[だ]し.
+func Furigana(segments []Segment) string {
+	var s string
+	for i, seg := range segments {
+		if seg.Reading == "" {
+			s += seg.Text
+			continue
+		}
+		if i > 0 {
+			s += " "
+		}
+		s += seg.Text + "[" + seg.Reading + "]"
+	}
+	return s
+}
+
+// Spellings returns the word once per kanji spelling, each with that
+// spelling only. Kana-only words are returned as is.
+func (w *Word) Spellings() []*Word {
+	if len(w.Kanji) < 2 {
+		return []*Word{w}
+	}
+
+	var words []*Word
+	for _, k := range w.Kanji {
+		s := *w
+		s.Kanji = []string{k}
+		words = append(words, &s)
+	}
+	return words
+}
+
+// isKanaRune reports whether r is a hiragana or katakana letter. The
+// iteration marks and ヶ are written in place of kanji and not treated as
+// kana.
+func isKanaRune(r rune) bool {
+	switch r {
+	case 'ヶ', 'ヵ', '々', '〆':
+		return false
+	}
+	return unicode.Is(unicode.Hiragana, r) || unicode.Is(unicode.Katakana, r) || r == 'ー'
+}
+
+// toHiragana returns s with katakana replaced by hiragana, so both can be
+// compared.
+func toHiragana(s []rune) string {
+	out := make([]rune, len(s))
+	for i, r := range s {
+		if r >= 'ァ' && r <= 'ヶ' {
+			r -= 'ァ' - 'ぁ'
+		}
+		out[i] = r
+	}
+	return string(out)
+}
@@ -0,0 +1,50 @@
+package conjugate
+
+import (
+	"testing"
+)
+
+func TestAlign(t *testing.T) {
+	tests := []struct {
+		kanji    string
+		kana     string
+		furigana string
+	}{
+		{"食べる", "たべる", "食[た]べる"},
+		{"勉強する", "べんきょうする", "勉強[べんきょう]する"},
+		{"貸し出し", "かしだし", "貸[か]し 出[だ]し"},
+		{"悲しい", "かなしい", "悲[かな]しい"},
+		{"来ない", "こない", "来[こ]ない"},
+		{"取り扱い", "とりあつかい", "取[と]り 扱[あつか]い"},
+		{"ヶ月", "かげつ", "ヶ月[かげつ]"},
+		{"パン屋", "ぱんや", "パン 屋[や]"},
+		{"食べる", "のむ", ""},
+	}
+
+	for _, tt := range tests {
+		segments := Align(tt.kanji, tt.kana)
+		if got := Furigana(segments); got != tt.furigana {
+			t.Errorf("%s (%s): got %q, want %q", tt.kanji, tt.kana, got, tt.furigana)
+		}
+	}
+}
+
+func TestSpellings(t *testing.T) {
+	w := &Word{Kana: "たべる", Kanji: []string{"食べる", "喰べる"}, Gloss: []*Gloss{{Pos: []string{"v1"}}}}
+
+	var got []string
+	for _, c := range w.Conjugations() {
+		if c.Form == Past {
+			got = c.Kanji(w, true, false)
+		}
+	}
+	if len(got) != 2 || got[0] != "食べた" || got[1] != "喰べた" {
+		t.Errorf("past of every spelling: got %v", got)
+	}
+
+	// a kanji spelling without okurigana can't be inflected
+	w = &Word{Kana: "かく", Kanji: []string{"書"}, Gloss: []*Gloss{{Pos: []string{"v5k"}}}}
+	if kana, kanji, _ := Conjugate(w, Past, Positive, Plain); kana != "かいた" || kanji != "" {
+		t.Errorf("spelling without okurigana: got %s, %s", kana, kanji)
+	}
+}
@@ -0,0 +1,180 @@
+package conjugate
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mistake is a wrong answer for a form of a word, built the way learners
+// commonly get it wrong.
+type Mistake struct {
+	Kana  string
+	Kanji string
+	// Reason describes the mistake, e.g. "conjugated as ichidan verb"
+	Reason string
+}
+
+// the classes a word of a class is mistakenly conjugated as
+var wrongClasses = map[string][]string{
+	"v1":     {"v5r"},
+	"v1-s":   {"v1", "v5r"},
+	"v5r":    {"v1"},
+	"v5r-i":  {"v5r"},
+	"v5aru":  {"v5r"},
+	"v5k-s":  {"v5k"},
+	"v5u-s":  {"v5u"},
+	"vk":     {"v1", "v5r"},
+	"vs-i":   {"v1"},
+	"adj-i":  {"adj-na"},
+	"adj-ix": {"adj-i"},
+	"adj-na": {"adj-i"},
+}
+
+var classNames = map[string]string{
+	"v1":     "ichidan verb",
+	"v5r":    "godan verb",
+	"v5k":    "regular く verb",
+	"v5u":    "regular う verb",
+	"adj-i":  "い-adjective",
+	"adj-na": "な-adjective",
+}
+
+// the godan endings whose 音便 is mixed up with each other, see ToOnbinkei
+var onbinEndings = []string{"つ", "く", "む", "ぐ", "す"}
+
+// the most 音便 mistakes returned, so that they don't crowd out the others
+const maxOnbinMistakes = 2
+
+// the forms mixed up with a form
+var neighbours = map[Form][]Form{
+	Present:          {Past},
+	Past:             {TeForm, Present},
+	TeForm:           {Past, Conditional},
+	Conditional:      {Provisional, Alternative},
+	Provisional:      {Conditional},
+	Passive:          {Potential, Causative},
+	Potential:        {Passive},
+	Causative:        {CausativePassive, Passive},
+	CausativePassive: {Causative, Passive},
+	Conjectural:      {Volitional},
+	Volitional:       {Imperative, Conjectural},
+	Imperative:       {Volitional},
+	Alternative:      {Conditional, Past},
+	Desiderative:     {Volitional},
+	Progressive:      {TeShimau},
+	TeShimau:         {Progressive},
+	Zu:               {Zuni},
+	Zuni:             {Zu},
+	Adverbial:        {TeForm},
+}
+
+// Mistakes returns wrong answers for the conjugation c of w, the most
+// likely mistakes first: conjugating w as a word of another class, using
+// the 音便 of another godan ending, the other politeness, a neighbouring
+// form and the other polarity. All of them are built by the conjugation
+// rules and none is spelled like the correct answer or another mistake.
+func Mistakes(w *Word, c Conjugation, positive bool, polite bool) []Mistake {
+	kana, _ := c.Exec(w, positive, polite)
+	if kana == "" {
+		return nil
+	}
+
+	var mistakes []Mistake
+	seen := map[string]bool{kana: true}
+	add := func(kana string, kanji string, reason string) {
+		if kana != "" && !seen[kana] {
+			seen[kana] = true
+			mistakes = append(mistakes, Mistake{kana, kanji, reason})
+		}
+	}
+
+	for _, class := range wrongClasses[w.Class()] {
+		wrong := w.as(class)
+		if m, ok := wrong.conjugation(c.Form); ok {
+			k, kanji := m.Exec(wrong, positive, polite)
+			add(k, kanji, "conjugated as "+classNames[class])
+		}
+	}
+
+	onbin := w.onbinMistakes(c, positive, polite)
+	for i := 0; i < len(onbin) && i < maxOnbinMistakes; i++ {
+		add(onbin[i].Kana, onbin[i].Kanji, onbin[i].Reason)
+	}
+
+	if c.Has(w, positive, !polite) {
+		k, kanji := c.Exec(w, positive, !polite)
+		if polite {
+			add(k, kanji, "plain instead of polite")
+		} else {
+			add(k, kanji, "polite instead of plain")
+		}
+	}
+
+	for _, f := range neighbours[c.Form] {
+		if n, ok := w.conjugation(f); ok && n.Has(w, positive, polite) {
+			k, kanji := n.Exec(w, positive, polite)
+			add(k, kanji, fmt.Sprintf("%s instead of %s", strings.ToLower(n.Name), strings.ToLower(c.Name)))
+		}
+	}
+
+	if c.Has(w, !positive, polite) {
+		k, kanji := c.Exec(w, !positive, polite)
+		if positive {
+			add(k, kanji, "negative instead of positive")
+		} else {
+			add(k, kanji, "positive instead of negative")
+		}
+	}
+
+	return mistakes
+}
+
+// onbinMistakes returns the form built with the 音便 of other godan
+// endings, e.g. 書って for 書いて, leaving out the correct one. There are
+// none if the form isn't built from the 音便 of w.
+func (w *Word) onbinMistakes(c Conjugation, positive bool, polite bool) []Mistake {
+	if w.isIchidan() || w.isSuru() || !strings.HasPrefix(w.Class(), "v5") {
+		return nil
+	}
+
+	stem, kstem := w.ToStem()
+	ending := func(e string) *Word {
+		o := w.as("v5k")
+		o.Kana = stem + e
+		if kstem != "" {
+			o.Kanji[0] = kstem + e
+		} else {
+			o.Kanji[0] = ""
+		}
+		return o
+	}
+
+	// only a つ verb tells the 音便 っ apart from the 連用形 ち
+	if k, _ := c.Exec(ending("つ"), positive, polite); !strings.HasPrefix(k, stem+"っ") {
+		return nil
+	}
+
+	correct, _ := c.Exec(w, positive, polite)
+	var mistakes []Mistake
+	for _, e := range onbinEndings {
+		if k, kanji := c.Exec(ending(e), positive, polite); k != correct {
+			mistakes = append(mistakes, Mistake{k, kanji, fmt.Sprintf("音便 of a %s verb", e)})
+		}
+	}
+	return mistakes
+}
+
+// as returns a copy of w conjugated as a word of the given class.
+func (w *Word) as(class string) *Word {
+	return &Word{ID: w.ID, Kana: w.Kana, Kanji: []string{w.Kanji[0]}, Gloss: []*Gloss{{Pos: []string{class}}}}
+}
+
+// conjugation returns the conjugation of w building form.
+func (w *Word) conjugation(form Form) (Conjugation, bool) {
+	for _, c := range w.Conjugations() {
+		if c.Form == form {
+			return c, true
+		}
+	}
+	return Conjugation{}, false
+}
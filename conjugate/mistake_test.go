@@ -0,0 +1,65 @@
+package conjugate
+
+import (
+	"testing"
+)
+
+func TestMistakes(t *testing.T) {
+	tests := []struct {
+		word     string
+		class    string
+		form     Form
+		positive bool
+		polite   bool
+		mistake  string
+		reason   string
+	}{
+		{"帰る(かえる)", "v5r", Present, true, true, "帰ます", "conjugated as ichidan verb"},
+		{"食べる(たべる)", "v1", Past, false, false, "食べらなかった", "conjugated as godan verb"},
+		{"書く(かく)", "v5k", TeForm, true, false, "書って", "音便 of a つ verb"},
+		{"読む(よむ)", "v5m", Past, true, false, "読いた", "音便 of a く verb"},
+		{"話す(はなす)", "v5s", TeForm, true, false, "話って", "音便 of a つ verb"},
+		{"行く(いく)", "v5k-s", Past, true, false, "行いた", "conjugated as regular く verb"},
+		{"書く(かく)", "v5k", Past, true, true, "書いた", "plain instead of polite"},
+		{"書く(かく)", "v5k", TeForm, true, false, "書いた", "past tense instead of te form"},
+		{"高い(たかい)", "adj-i", Past, true, false, "高いだった", "conjugated as な-adjective"},
+		{"来る(くる)", "vk", Present, false, false, "くない", "conjugated as ichidan verb"},
+	}
+
+	for _, tt := range tests {
+		w := fixture(tt.class, tt.word)
+		c, _ := w.conjugation(tt.form)
+
+		found := false
+		for _, m := range Mistakes(w, c, tt.positive, tt.polite) {
+			if m.Kanji == tt.mistake || m.Kana == tt.mistake {
+				found = true
+				if m.Reason != tt.reason {
+					t.Errorf("%s %s: reason of %s is %q, want %q", tt.word, c.Name, tt.mistake, m.Reason, tt.reason)
+				}
+			}
+		}
+		if !found {
+			t.Errorf("%s %s: %s missing in %v", tt.word, c.Name, tt.mistake, Mistakes(w, c, tt.positive, tt.polite))
+		}
+	}
+}
+
+// TestMistakesGolden checks that no mistake is a correct answer.
+func TestMistakesGolden(t *testing.T) {
+	for _, e := range readGolden(t) {
+		w := fixture(e.class, e.word)
+		for _, c := range w.Conjugations() {
+			for _, positive := range []bool{true, false} {
+				for _, polite := range []bool{false, true} {
+					kana, _ := c.Exec(w, positive, polite)
+					for _, m := range Mistakes(w, c, positive, polite) {
+						if m.Kana == kana || m.Kana == "" {
+							t.Errorf("%s %s: mistake %+v is the answer %s", e.word, c.Name, m, kana)
+						}
+					}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,175 @@
+package conjugate
+
+import (
+	"strings"
+)
+
+// Trace lists the steps taken to build a form of a word, e.g.
+// 書く → godan く → 音便 い → 書い + た → 書いた.
+type Trace []string
+
+func (t Trace) String() string {
+	return strings.Join(t, " → ")
+}
+
+// Explain conjugates w like Conjugate and returns how the form was built.
+func Explain(w *Word, form Form, polarity Polarity, politeness Politeness) (Trace, error) {
+	t := w.traced()
+	kana, kanji, err := Conjugate(t, form, polarity, politeness)
+	if err != nil {
+		return nil, err
+	}
+	return t.trace.result(kana, kanji), nil
+}
+
+// Explain applies the chain to w like Apply and returns how the result
+// was built.
+func (c *Chain) Explain(w *Word) (Trace, error) {
+	t := w.traced()
+	kana, kanji, err := c.Apply(t)
+	if err != nil {
+		return nil, err
+	}
+	return t.trace.result(kana, kanji), nil
+}
+
+// tracer records the trace of a word. Bases are noted when they are built
+// but only show up in the trace once an ending is attached to them, as
+// building one base often involves building others.
+type tracer struct {
+	steps Trace
+	// base is the kana of the last noted base, described by baseNote
+	base     string
+	baseNote string
+}
+
+// traced returns a copy of w that records its conjugation.
+func (w *Word) traced() *Word {
+	t := *w
+	t.trace = &tracer{}
+	t.trace.word(&t)
+	return &t
+}
+
+// word adds the dictionary form of w and its conjugation class.
+func (t *tracer) word(w *Word) {
+	if t == nil {
+		return
+	}
+
+	kana, kanji := w.ToRentaikei()
+	t.add(spelling(kana, kanji))
+	t.add(w.describe())
+}
+
+// note describes the base spelled kana.
+func (t *tracer) note(note string, kana string) {
+	if t == nil {
+		return
+	}
+	t.base, t.baseNote = kana, note
+}
+
+// attach adds ending to the base and the note describing it, if any.
+func (t *tracer) attach(kana string, kanji string, ending string) {
+	if t == nil {
+		return
+	}
+
+	if t.baseNote != "" && t.base == kana {
+		t.add(t.baseNote)
+	}
+	t.base, t.baseNote = "", ""
+
+	switch {
+	case ending == "":
+	case kana == "":
+		t.add(ending)
+	default:
+		t.add(spelling(kana, kanji) + " + " + ending)
+	}
+}
+
+// result adds the final form and returns the trace.
+func (t *tracer) result(kana string, kanji string) Trace {
+	t.add(spelling(kana, kanji))
+	return t.steps
+}
+
+// add appends step unless it repeats the previous one.
+func (t *tracer) add(step string) {
+	if step != "" && (len(t.steps) == 0 || t.steps[len(t.steps)-1] != step) {
+		t.steps = append(t.steps, step)
+	}
+}
+
+// attach returns base + ending and records it in the trace.
+func (w *Word) attach(kana string, kanji string, ending string) (string, string) {
+	w.trace.attach(kana, kanji, ending)
+
+	if kanji != "" {
+		return kana + ending, kanji + ending
+	} else {
+		return kana + ending, kanji
+	}
+}
+
+// noteBase records that the base kana named name was built, describing
+// how it differs from the dictionary form: 未然形 か for 書く.
+func (w *Word) noteBase(name string, kana string) {
+	if w.trace == nil {
+		return
+	}
+
+	dict, _ := w.ToRentaikei()
+	from, to := []rune(dict), []rune(kana)
+	for len(from) > 0 && len(to) > 0 && from[0] == to[0] {
+		from, to = from[1:], to[1:]
+	}
+
+	switch {
+	case len(from) == 0 && len(to) == 0:
+		return
+	case len(to) == 0:
+		w.trace.note(name+" drops "+string(from), kana)
+	default:
+		w.trace.note(name+" "+string(to), kana)
+	}
+}
+
+// describe returns the conjugation class of the word in words:
+// "godan く", "ichidan る" or "i-adjective".
+func (w *Word) describe() string {
+	kana, _ := w.ToRentaikei()
+	last := []rune(kana)
+	if len(last) > 0 {
+		last = last[len(last)-1:]
+	}
+
+	switch c := w.Class(); {
+	case c == "vk":
+		return "irregular 来る"
+	case w.isSuru():
+		return "irregular する"
+	case c == "vz":
+		return "irregular ずる"
+	case w.isIchidan():
+		return "ichidan る"
+	case strings.HasPrefix(c, "v5"):
+		return "godan " + string(last)
+	case c == "adj-na":
+		return "na-adjective"
+	case w.IsAdjective():
+		return "i-adjective"
+	}
+	return ""
+}
+
+// spelling returns the kanji spelling if there is one and the kana one
+// otherwise.
+func spelling(kana string, kanji string) string {
+	if kanji != "" {
+		return kanji
+	}
+	return kana
+}
@@ -0,0 +1,55 @@
+package conjugate
+
+import (
+	"testing"
+)
+
+func TestExplain(t *testing.T) {
+	tests := []struct {
+		word  string
+		class string
+		chain string
+		trace string
+	}{
+		{"書く(かく)", "v5k", "past", "書く → godan く → 音便 い → 書い + た → 書いた"},
+		{"読む(よむ)", "v5m", "te", "読む → godan む → 音便 ん → 読ん + で → 読んで"},
+		{"食べる(たべる)", "v1", "negative,polite", "食べる → ichidan る → 連用形 drops る → 食べ + ません → 食べません"},
+		{"書く(かく)", "v5k", "volitional", "書く → godan く → 未然形 こ → 書こ + う → 書こう"},
+		{"書く(かく)", "v5k", "imperative", "書く → godan く → 命令形 け → 書け"},
+		{"ある", "v5r-i", "past,negative", "ある → godan る → ない replaces ある → なかった"},
+		{"する", "vs-i", "zuni,negative", "する → irregular する → 未然形 せ → せ + ず → せず + に → せずに"},
+		{"いい", "adj-ix", "past", "いい → i-adjective → 語幹 よ → よ + かった → よかった"},
+		{"書く(かく)", "v5k", "causative,passive,past", "書く → godan く → 未然形 か → 書か + せる → 書かせる → ichidan る → " +
+			"未然形 drops る → 書かせ + られる → 書かせられる → ichidan る → 連用形 drops る → 書かせられ + た → 書かせられた"},
+	}
+
+	for _, tt := range tests {
+		c, _ := ParseChain(tt.chain)
+		trace, err := c.Explain(fixture(tt.class, tt.word))
+		if err != nil || trace.String() != tt.trace {
+			t.Errorf("%s %s: got %s, %v, want %s", tt.word, tt.chain, trace, err, tt.trace)
+		}
+	}
+}
+
+// TestExplainGolden checks that every trace ends in the form Conjugate
+// builds.
+func TestExplainGolden(t *testing.T) {
+	for _, e := range readGolden(t) {
+		w := fixture(e.class, e.word)
+
+		for _, c := range w.Conjugations() {
+			for i, cell := range e.forms[c.Name] {
+				if cell == "" {
+					continue
+				}
+
+				trace, err := Explain(w, c.Form, Polarity(i/2), Politeness(i%2))
+				kana, kanji := splitCell(cell)
+				if err != nil || trace[len(trace)-1] != spelling(kana, kanji) {
+					t.Errorf("%s (%s) %s %s: trace %s, %v", e.word, e.class, c.Name, columns[i], trace, err)
+				}
+			}
+		}
+	}
+}
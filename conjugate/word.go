@@ -0,0 +1,112 @@
+// Package conjugate builds the inflected forms of japanese verbs and
+// adjectives from their dictionary form and JMdict part of speech tags.
+package conjugate
+
+import (
+	"errors"
+)
+
+// Form identifies a conjugation like the past tense or the te-form.
+type Form int
+
+const (
+	Present Form = iota
+	Past
+	TeForm
+	Conditional
+	Provisional
+	Passive
+	Causative
+	CausativePassive
+	Conjectural
+	Alternative
+	Imperative
+	Adverbial
+	Potential
+	Volitional
+	Desiderative
+	Progressive
+	TeShimau
+	Zu
+	Zuni
+)
+
+type Polarity int
+
+const (
+	Positive Polarity = iota
+	Negative
+)
+
+type Politeness int
+
+const (
+	Plain Politeness = iota
+	Polite
+)
+
+var ErrUnknownForm = errors.New("conjugate: form not applicable to word")
+
+// Gloss is a single sense of a word, its parts of speech and meanings.
+type Gloss struct {
+	Pos     []string
+	Meaning []string
+}
+
+// Word is a dictionary entry. Kana holds the reading and Kanji the
+// kanji spellings, whose first element is empty for kana-only words.
+type Word struct {
+	ID    int
+	Kana  string
+	Kanji []string
+	Gloss []*Gloss
+
+	// trace records the conjugation of words passed to Explain
+	trace *tracer
+}
+
+// Conjugation describes how a form is built. Exec returns the kana and
+// kanji spelling of the form for the given polarity and politeness, or
+// empty strings if the form doesn't exist that way. Rule holds the textual
+// rule per conjugation class.
+type Conjugation struct {
+	Form Form
+	Exec func(*Word, bool, bool) (string, string)
+	Name string
+	Rule map[string]string
+}
+
+// Conjugate returns the kana and kanji spelling of the given form of w.
+// The kanji spelling is empty for kana-only words.
+func Conjugate(w *Word, form Form, polarity Polarity, politeness Politeness) (string, string, error) {
+	for _, c := range w.Conjugations() {
+		if c.Form == form {
+			kana, kanji := c.Exec(w, polarity == Positive, politeness == Polite)
+			if kana == "" {
+				break
+			}
+			return kana, kanji, nil
+		}
+	}
+
+	return "", "", ErrUnknownForm
+}
+
+// Has reports whether the conjugation exists with the given polarity and
+// politeness, e.g. the ず form only exists as a negative.
+func (c Conjugation) Has(w *Word, positive bool, polite bool) bool {
+	kana, _ := c.Exec(w, positive, polite)
+	return kana != ""
+}
+
+// Kanji returns the kanji spelling of the conjugation for every kanji
+// spelling of w, leaving out the ones that can't be inflected.
+func (c Conjugation) Kanji(w *Word, positive bool, polite bool) []string {
+	var spellings []string
+	for _, s := range w.Spellings() {
+		if _, kanji := c.Exec(s, positive, polite); kanji != "" {
+			spellings = append(spellings, kanji)
+		}
+	}
+	return spellings
+}
@@ -0,0 +1,145 @@
+package dict
+
+import (
+	"bufio"
+	"strconv"
+	"strings"
+
+	"github.com/tsurai/msyu/conjugate"
+)
+
+var accentSchema = []string{
+	"DROP TABLE IF EXISTS accent",
+	"CREATE TABLE accent (id INTEGER PRIMARY KEY, fk INTEGER NOT NULL, kana TEXT NOT NULL, downstep INTEGER NOT NULL)",
+	"CREATE INDEX accent_fk ON accent (fk)",
+}
+
+// adds an accent to every entry spelled like the accent file entry. Kana
+// words are matched by their reading alone, but only if the entry has no
+// kanji spelling or the reading isn't written with one, so that they don't
+// match every kanji word read the same.
+const accentInsert = "INSERT INTO accent (fk, kana, downstep) " +
+	"SELECT DISTINCT r_ele.fk, r_ele.value, ? FROM r_ele " +
+	"LEFT JOIN k_ele ON r_ele.fk = k_ele.fk " +
+	"WHERE r_ele.value = ? AND (k_ele.value = ? OR " +
+	"(r_ele.value = ? AND (k_ele.value IS NULL OR r_ele.nokanji = 1)))"
+
+// ImportAccents reads the pitch accents from the Kanjium accents.txt file
+// at path, which may be gzip compressed, and replaces the accent table
+// with them. Every line holds a word, its reading (empty for kana words)
+// and a comma separated list of downstep positions, optionally prefixed
+// by a part of speech in parentheses like (名)0. The accents are added to
+// the JMdict entries with the same spelling, so JMdict has to be imported
+// first. progress, if not nil, is called with the number of lines read so
+// far after every batch. It returns the number of accents added.
+func ImportAccents(path string, progress func(int)) (int, error) {
+	r, err := openImport(path)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	for _, stmt := range accentSchema {
+		if _, err := database.Exec(stmt); err != nil {
+			return 0, err
+		}
+	}
+
+	tx, err := database.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	added, lines := 0, 0
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		fields := strings.Split(s.Text(), "\t")
+		if len(fields) < 3 || fields[0] == "" {
+			continue
+		}
+
+		word, kana := fields[0], fields[1]
+		if kana == "" {
+			kana = word
+		}
+
+		for _, downstep := range parseDownsteps(fields[2]) {
+			res, err := tx.Exec(accentInsert, downstep, kana, word, word)
+			if err != nil {
+				tx.Rollback()
+				return added, err
+			}
+			n, _ := res.RowsAffected()
+			added += int(n)
+		}
+
+		lines++
+		if lines%importBatchSize == 0 {
+			if err := tx.Commit(); err != nil {
+				return added, err
+			}
+			if progress != nil {
+				progress(lines)
+			}
+			if tx, err = database.Begin(); err != nil {
+				return added, err
+			}
+		}
+	}
+
+	if err := s.Err(); err != nil {
+		tx.Rollback()
+		return added, err
+	}
+
+	return added, tx.Commit()
+}
+
+// parseDownsteps returns the downstep positions of a list like "0,2" or
+// "(名)0,(副)1", leaving out duplicates.
+func parseDownsteps(s string) []int {
+	var downsteps []int
+	seen := make(map[int]bool)
+
+	for _, f := range strings.Split(s, ",") {
+		if i := strings.LastIndex(f, ")"); i >= 0 {
+			f = f[i+1:]
+		}
+
+		n, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil || n < 0 || seen[n] {
+			continue
+		}
+		seen[n] = true
+		downsteps = append(downsteps, n)
+	}
+
+	return downsteps
+}
+
+// Accents returns the downstep positions of the word's reading, nil if
+// they are unknown or no accents have been imported. A downstep of 0
+// means the pitch doesn't drop (heiban), n the pitch drops after the nth
+// mora.
+func Accents(w *conjugate.Word) ([]int, error) {
+	if imported, err := db_has_table("accent"); err != nil || !imported {
+		return nil, err
+	}
+
+	rows, err := db_query("SELECT downstep FROM accent WHERE fk = ? AND kana = ? ORDER BY id", w.ID, w.Kana)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var accents []int
+	for rows.Next() {
+		var n int
+		if err := rows.Scan(&n); err != nil {
+			return nil, err
+		}
+		accents = append(accents, n)
+	}
+
+	return accents, rows.Err()
+}
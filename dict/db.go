@@ -0,0 +1,534 @@
+// Package dict provides access to the JMdict dictionary database.
+package dict
+
+import (
+	"database/sql"
+	"errors"
+	"strings"
+	"sync"
+
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/tsurai/msyu/conjugate"
+)
+
+const (
+	// lang
+	EN  = 0
+	JAP = 1
+	// filter
+	VERB       = 10
+	ADJ        = 11
+	NOUN       = 12
+	CONJUGABLE = 13
+	ALL        = 14
+)
+
+var (
+	ErrMissingParameter = errors.New("dict: missing parameter")
+	ErrInvalidParameter = errors.New("dict: invalid parameter")
+	ErrUnknownMode      = errors.New("dict: unknown search mode")
+	ErrUnknownFilter    = errors.New("dict: unknown filter")
+	// ErrNotImported is returned for criteria needing data missing from
+	// the database, which lacks the JLPT levels or predates the import of
+	// the JMdict tags.
+	ErrNotImported = errors.New("dict: data not imported")
+)
+
+// matches every verb class the conjugation engine knows about
+const verbFilter = " (entity.entity LIKE 'v1%' OR entity.entity LIKE 'v5%' OR " +
+	"entity.entity LIKE 'vs%' OR entity.entity = 'vk' OR entity.entity = 'vz') "
+
+// matches the i- and na-adjectives
+const adjFilter = " (entity.entity LIKE 'adj-i%' OR entity.entity = 'adj-na') "
+
+// restricts a k_ele join to the kanji spellings of the reading r_ele,
+// following its re_nokanji and re_restr elements
+const kanjiRestr = " AND r_ele.nokanji = 0 AND (k_ele.value IN (SELECT value FROM re_restr WHERE re_restr.fk = r_ele.id) " +
+	"OR NOT EXISTS (SELECT 1 FROM re_restr WHERE re_restr.fk = r_ele.id)) "
+
+var database *sql.DB = nil
+
+// prepared statements by query, kept until Close
+var (
+	stmts     = make(map[string]*sql.Stmt)
+	stmtsLock sync.Mutex
+)
+
+// Init opens the dictionary database at path.
+func Init(path string) error {
+	var err error
+	database, err = sql.Open("sqlite3", path)
+	return err
+}
+
+func Close() {
+	stmtsLock.Lock()
+	for q, stmt := range stmts {
+		stmt.Close()
+		delete(stmts, q)
+	}
+	stmtsLock.Unlock()
+
+	database.Close()
+}
+
+// db_prepare returns the prepared statement for query, preparing it on
+// first use.
+func db_prepare(query string) (*sql.Stmt, error) {
+	stmtsLock.Lock()
+	defer stmtsLock.Unlock()
+
+	if stmt, ok := stmts[query]; ok {
+		return stmt, nil
+	}
+
+	stmt, err := database.Prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	stmts[query] = stmt
+
+	return stmt, nil
+}
+
+// db_query runs query as a cached prepared statement with the given args.
+func db_query(query string, args ...interface{}) (*sql.Rows, error) {
+	stmt, err := db_prepare(query)
+	if err != nil {
+		return nil, err
+	}
+	return stmt.Query(args...)
+}
+
+// db_has_table reports whether the table name exists. Tables filled by
+// the optional imports are missing until those have been run.
+func db_has_table(name string) (bool, error) {
+	rows, err := db_query("SELECT name FROM sqlite_master WHERE type = 'table' AND name = ?", name)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	return rows.Next(), rows.Err()
+}
+
+// db_like returns a LIKE pattern matching any value containing s. Use it
+// together with ESCAPE '\'.
+func db_like(s string) string {
+	return "%" + db_escape(s) + "%"
+}
+
+// db_prefix returns a LIKE pattern matching any value starting with s. Use
+// it together with ESCAPE '\'.
+func db_prefix(s string) string {
+	return db_escape(s) + "%"
+}
+
+func db_escape(s string) string {
+	s = strings.Replace(s, `\`, `\\`, -1)
+	s = strings.Replace(s, "%", `\%`, -1)
+	return strings.Replace(s, "_", `\_`, -1)
+}
+
+// db_filter returns the sql condition matching the given filter.
+func db_filter(filter int) string {
+	switch filter {
+	case VERB:
+		return verbFilter
+	case ADJ:
+		return adjFilter
+	case CONJUGABLE:
+		return " (" + verbFilter + " OR " + adjFilter + ") "
+	case ALL:
+		return " 1 "
+	}
+	return ""
+}
+
+// db_jap_query returns the query for words with a kana or kanji spelling
+// matching cond, e.g. "= ?". Both spellings are matched against the same
+// argument. Only the kanji spellings a reading may be written with are
+// matched and returned.
+func db_jap_query(sqlfilter string, cond string) string {
+	return "SELECT r_ele.fk, r_ele.value, " +
+		"GROUP_CONCAT(DISTINCT entity.entity), " +
+		"GROUP_CONCAT(DISTINCT gloss.value), " +
+		"GROUP_CONCAT(DISTINCT k_ele.value) FROM r_ele, gloss, sense " +
+		"LEFT JOIN k_ele ON sense.fk = k_ele.fk" + kanjiRestr +
+		"LEFT OUTER JOIN pos ON sense.id = pos.fk " +
+		"LEFT OUTER JOIN entity ON pos.entity = entity.id " +
+		"WHERE r_ele.id IN (SELECT r_ele.id FROM r_ele, sense, pos, entity WHERE " + sqlfilter +
+		"AND r_ele.fk = sense.fk AND sense.id = pos.fk AND pos.entity = entity.id) " +
+		"AND (r_ele.value " + cond + " " +
+		"OR r_ele.id IN (SELECT r_ele.id FROM r_ele, k_ele WHERE r_ele.fk = k_ele.fk AND k_ele.value " + cond + kanjiRestr + ")) " +
+		"AND r_ele.fk = sense.fk AND gloss.fk = sense.id " +
+		"GROUP BY r_ele.id, sense.id ORDER BY length(r_ele.value), r_ele.fk, r_ele.id, sense.id"
+}
+
+func db_parse_results(rows *sql.Rows) ([]*conjugate.Word, int) {
+	var rvalue sql.NullString
+	var kvalue sql.NullString
+	var pos sql.NullString
+	var meaning sql.NullString
+	var words []*conjugate.Word
+
+	id := 0
+	lastId := 0
+	lastKana := ""
+	for rows.Next() {
+		var g []*conjugate.Gloss
+		rows.Scan(&id, &rvalue, &pos, &meaning, &kvalue)
+
+		// every reading of an entry is a word of its own
+		if lastId != id || lastKana != rvalue.String {
+			lastId, lastKana = id, rvalue.String
+			g = append(g, &conjugate.Gloss{Pos: strings.Split(pos.String, ","), Meaning: strings.Split(meaning.String, ",")})
+			words = append(words, &conjugate.Word{ID: id, Kana: rvalue.String, Kanji: strings.Split(kvalue.String, ","), Gloss: g})
+		} else {
+			words[len(words)-1].Gloss = append(words[len(words)-1].Gloss, &conjugate.Gloss{Pos: strings.Split(pos.String, ","), Meaning: strings.Split(meaning.String, ",")})
+		}
+	}
+
+	return words, len(words)
+}
+
+// Search returns the words matching w. mode selects whether w is matched
+// against the japanese spellings (JAP) or the english glosses (EN).
+func Search(w string, mode int, filter int) ([]*conjugate.Word, error) {
+	if w == "" {
+		return nil, ErrMissingParameter
+	}
+
+	sqlfilter := db_filter(filter)
+	if sqlfilter == "" {
+		return nil, ErrUnknownFilter
+	}
+
+	var query string
+	var args []interface{}
+	switch mode {
+	case JAP:
+		query = db_jap_query(sqlfilter, "LIKE ? ESCAPE '\\'")
+		args = []interface{}{db_like(w), db_like(w)}
+
+	case EN:
+		query = "SELECT sense.fk, r_ele.value, " +
+			"group_concat(DISTINCT entity.entity), " +
+			"group_concat(DISTINCT gloss.value), " +
+			"group_concat(DISTINCT k_ele.value) FROM r_ele, gloss, sense " +
+			"LEFT JOIN k_ele ON sense.fk = k_ele.fk" + kanjiRestr +
+			"LEFT JOIN pos ON sense.id = pos.fk " +
+			"LEFT JOIN entity ON pos.entity = entity.id " +
+			"WHERE gloss.fk = sense.id AND sense.fk = r_ele.fk AND " + sqlfilter +
+			"AND sense.fk IN (SELECT sense.fk FROM sense, gloss WHERE gloss.value LIKE ? ESCAPE '\\' " +
+			"AND gloss.fk = sense.id) GROUP BY r_ele.id, sense.id, pos.fk " +
+			"ORDER BY length(r_ele.value), sense.fk, r_ele.id, sense.id"
+		args = []interface{}{db_like(w)}
+	default:
+		return nil, ErrUnknownMode
+	}
+
+	rows, err := db_query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	words, _ := db_parse_results(rows)
+
+	return words, rows.Err()
+}
+
+// Lookup returns the words spelled exactly w in kana or kanji.
+func Lookup(w string, filter int) ([]*conjugate.Word, error) {
+	if w == "" {
+		return nil, ErrMissingParameter
+	}
+
+	sqlfilter := db_filter(filter)
+	if sqlfilter == "" {
+		return nil, ErrUnknownFilter
+	}
+
+	rows, err := db_query(db_jap_query(sqlfilter, "= ?"), w, w)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	words, _ := db_parse_results(rows)
+
+	return words, rows.Err()
+}
+
+func RandomVerbs(n int) ([]*conjugate.Word, error) {
+	return RandomWords(n, VERB)
+}
+
+// RandomWords returns n random words matching filter.
+func RandomWords(n int, filter int) ([]*conjugate.Word, error) {
+	return RandomWordsMatching(n, filter, Criteria{})
+}
+
+// Criteria narrow down the words beyond the part of speech filter. The
+// zero value matches every word.
+type Criteria struct {
+	// Pos lists JMdict part of speech tags. A word must have a sense
+	// tagged with one of them or a tag they are a prefix of.
+	Pos []string
+	// Common restricts the words to readings JMdict marks as common, i.e.
+	// tagged ichi1, news1, spec1, spec2 or gai1. The nf01 to nf24 tags are
+	// part of news1.
+	Common bool
+	// JLPT lists the JLPT levels, 5 for N5 down to 1 for N1. A word must
+	// have one of them.
+	JLPT []int
+	// ExcludeArchaic leaves out the senses tagged archaic, obsolete or
+	// obscure, and so the words having only such senses, as well as the
+	// kanji spellings tagged rare (rK).
+	ExcludeArchaic bool
+}
+
+// the priority tags of common readings
+const commonCond = "AND EXISTS (SELECT 1 FROM re_pri WHERE re_pri.fk = r_ele.id " +
+	"AND re_pri.value IN ('ichi1', 'news1', 'spec1', 'spec2', 'gai1')) "
+
+// the misc tags of senses hardly in use anymore
+const archaicCond = "AND NOT EXISTS (SELECT 1 FROM misc, entity AS m WHERE misc.fk = sense.id " +
+	"AND misc.entity = m.id AND m.entity IN ('arch', 'obs', 'obsc')) "
+
+// the kanji spellings of an entry tagged rare
+const rareKanjiQuery = "SELECT k_ele.value FROM k_ele, ke_inf, entity AS i WHERE k_ele.fk = ? " +
+	"AND ke_inf.fk = k_ele.id AND ke_inf.entity = i.id AND i.entity = 'rK'"
+
+// check returns ErrNotImported if the tables the criteria need are missing.
+func (c Criteria) check() error {
+	var tables []string
+	if c.Common || c.ExcludeArchaic {
+		tables = append(tables, "re_pri", "misc", "ke_inf")
+	}
+	if len(c.JLPT) > 0 {
+		tables = append(tables, "jlpt")
+	}
+
+	for _, t := range tables {
+		if imported, err := db_has_table(t); err != nil {
+			return err
+		} else if !imported {
+			return ErrNotImported
+		}
+	}
+	return nil
+}
+
+// sql returns the condition matching the criteria, to be added to a query
+// joining a reading r_ele, one of its senses and the entity of the sense,
+// together with its arguments.
+func (c Criteria) sql() (string, []interface{}) {
+	var cond string
+	var args []interface{}
+
+	if len(c.Pos) > 0 {
+		var tags []string
+		for _, p := range c.Pos {
+			tags = append(tags, "entity.entity LIKE ? ESCAPE '\\'")
+			args = append(args, db_prefix(p))
+		}
+		cond += "AND (" + strings.Join(tags, " OR ") + ") "
+	}
+
+	if c.Common {
+		cond += commonCond
+	}
+
+	if len(c.JLPT) > 0 {
+		levels := strings.Repeat(", ?", len(c.JLPT))[2:]
+		cond += "AND EXISTS (SELECT 1 FROM jlpt WHERE jlpt.fk = r_ele.fk AND jlpt.kana = r_ele.value " +
+			"AND jlpt.level IN (" + levels + ")) "
+		for _, l := range c.JLPT {
+			args = append(args, l)
+		}
+	}
+
+	if c.ExcludeArchaic {
+		cond += archaicCond
+	}
+
+	return cond, args
+}
+
+// dropRareKanji removes the kanji spellings tagged rare from the words.
+// A word left without any is written in kana.
+func dropRareKanji(words []*conjugate.Word) error {
+	for _, w := range words {
+		rows, err := db_query(rareKanjiQuery, w.ID)
+		if err != nil {
+			return err
+		}
+		rare := make(map[string]bool)
+		for rows.Next() {
+			var k string
+			if err := rows.Scan(&k); err != nil {
+				rows.Close()
+				return err
+			}
+			rare[k] = true
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return err
+		}
+
+		var kanji []string
+		for _, k := range w.Kanji {
+			if !rare[k] {
+				kanji = append(kanji, k)
+			}
+		}
+		if len(kanji) == 0 {
+			kanji = []string{""}
+		}
+		w.Kanji = kanji
+	}
+	return nil
+}
+
+// Filter returns the words matching c. With c.ExcludeArchaic the rare
+// kanji spellings of the words are removed.
+func Filter(words []*conjugate.Word, c Criteria) ([]*conjugate.Word, error) {
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+	cond, args := c.sql()
+
+	query := "SELECT 1 FROM r_ele, sense, pos, entity WHERE r_ele.fk = ? AND r_ele.value = ? " +
+		"AND r_ele.fk = sense.fk AND sense.id = pos.fk AND pos.entity = entity.id " + cond + "LIMIT 1"
+
+	var matches []*conjugate.Word
+	for _, w := range words {
+		rows, err := db_query(query, append([]interface{}{w.ID, w.Kana}, args...)...)
+		if err != nil {
+			return nil, err
+		}
+		found := rows.Next()
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+
+		if found {
+			matches = append(matches, w)
+		}
+	}
+
+	if c.ExcludeArchaic {
+		if err := dropRareKanji(matches); err != nil {
+			return nil, err
+		}
+	}
+	return matches, nil
+}
+
+// RandomWordsMatching returns n random words matching filter and c, see
+// Filter.
+func RandomWordsMatching(n int, filter int, c Criteria) ([]*conjugate.Word, error) {
+	if n <= 0 {
+		return nil, ErrInvalidParameter
+	}
+
+	sqlfilter := db_filter(filter)
+	if sqlfilter == "" {
+		return nil, ErrUnknownFilter
+	}
+	if err := c.check(); err != nil {
+		return nil, err
+	}
+	cond, args := c.sql()
+
+	query := "SELECT r_ele.fk, r_ele.value, " +
+		"GROUP_CONCAT(DISTINCT entity.entity), " +
+		"GROUP_CONCAT(DISTINCT gloss.value), " +
+		"GROUP_CONCAT(DISTINCT k_ele.value) FROM r_ele, k_ele, gloss, sense " +
+		"LEFT OUTER JOIN pos ON sense.id = pos.fk " +
+		"LEFT OUTER JOIN entity ON pos.entity = entity.id " +
+		"WHERE r_ele.id IN (SELECT r_ele.id FROM r_ele, sense, pos, entity WHERE " + sqlfilter + cond +
+		"AND r_ele.fk = sense.fk AND sense.id = pos.fk AND pos.entity = entity.id ORDER BY RANDOM() LIMIT ?) " +
+		"AND sense.fk = k_ele.fk" + kanjiRestr + "AND r_ele.fk = sense.fk AND gloss.fk = sense.id " +
+		"GROUP BY r_ele.id, sense.id, pos.fk ORDER BY r_ele.fk, r_ele.id"
+
+	rows, err := db_query(query, append(args, n)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	w, _ := db_parse_results(rows)
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if c.ExcludeArchaic {
+		if err := dropRareKanji(w); err != nil {
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// GetWord returns the entry with the given id read as kana, nil if there
+// is none.
+func GetWord(id int, kana string) (*conjugate.Word, error) {
+	query := "SELECT r_ele.fk, r_ele.value, " +
+		"GROUP_CONCAT(DISTINCT entity.entity), " +
+		"GROUP_CONCAT(DISTINCT gloss.value), " +
+		"GROUP_CONCAT(DISTINCT k_ele.value) FROM r_ele, gloss, sense " +
+		"LEFT JOIN k_ele ON sense.fk = k_ele.fk" + kanjiRestr +
+		"LEFT OUTER JOIN pos ON sense.id = pos.fk " +
+		"LEFT OUTER JOIN entity ON pos.entity = entity.id " +
+		"WHERE r_ele.fk = ? AND r_ele.value = ? " +
+		"AND r_ele.fk = sense.fk AND gloss.fk = sense.id " +
+		"GROUP BY sense.id ORDER BY sense.id"
+
+	rows, err := db_query(query, id, kana)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	w, num := db_parse_results(rows)
+	if num == 0 {
+		return nil, rows.Err()
+	}
+
+	return w[0], nil
+}
+
+// AllWords returns every word matching filter, ordered by entry.
+func AllWords(filter int) ([]*conjugate.Word, error) {
+	sqlfilter := db_filter(filter)
+	if sqlfilter == "" {
+		return nil, ErrUnknownFilter
+	}
+
+	query := "SELECT r_ele.fk, r_ele.value, " +
+		"GROUP_CONCAT(DISTINCT entity.entity), " +
+		"GROUP_CONCAT(DISTINCT gloss.value), " +
+		"GROUP_CONCAT(DISTINCT k_ele.value) FROM r_ele, gloss, sense " +
+		"LEFT JOIN k_ele ON sense.fk = k_ele.fk" + kanjiRestr +
+		"LEFT OUTER JOIN pos ON sense.id = pos.fk " +
+		"LEFT OUTER JOIN entity ON pos.entity = entity.id " +
+		"WHERE r_ele.id IN (SELECT r_ele.id FROM r_ele, sense, pos, entity WHERE " + sqlfilter +
+		"AND r_ele.fk = sense.fk AND sense.id = pos.fk AND pos.entity = entity.id) " +
+		"AND r_ele.fk = sense.fk AND gloss.fk = sense.id " +
+		"GROUP BY r_ele.id, sense.id ORDER BY r_ele.fk, r_ele.id, sense.id"
+
+	rows, err := db_query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	words, _ := db_parse_results(rows)
+
+	return words, rows.Err()
+}
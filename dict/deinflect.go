@@ -0,0 +1,60 @@
+package dict
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tsurai/msyu/conjugate"
+)
+
+// Deinflected is a dictionary word an inflected form was traced back to.
+// Chain lists the inflections leading from the word to the form, innermost
+// first.
+type Deinflected struct {
+	Word  *conjugate.Word
+	Chain []string
+}
+
+// Deinflect returns the dictionary words s may be an inflected form of.
+// Only candidates whose part of speech can produce the inflection chain
+// are returned, the shortest chains first.
+func Deinflect(s string) ([]*Deinflected, error) {
+	var results []*Deinflected
+	seen := make(map[string]bool)
+
+	for _, d := range conjugate.Deinflect(s) {
+		spellings := []string{d.Word}
+		if d.IsSuru() && d.Word != "する" {
+			// nouns taking する are stored without it
+			spellings = append(spellings, strings.TrimSuffix(d.Word, "する"))
+		}
+
+		for _, spelling := range spellings {
+			words, err := Lookup(spelling, ALL)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, w := range words {
+				if !d.Matches(w) {
+					continue
+				}
+
+				key := strconv.Itoa(w.ID) + w.Kana + strings.Join(d.Chain, ",")
+				if seen[key] {
+					continue
+				}
+				seen[key] = true
+
+				results = append(results, &Deinflected{Word: w, Chain: d.Chain})
+			}
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return len(results[i].Chain) < len(results[j].Chain)
+	})
+
+	return results, nil
+}
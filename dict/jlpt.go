@@ -0,0 +1,134 @@
+package dict
+
+import (
+	"encoding/csv"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var jlptSchema = []string{
+	"DROP TABLE IF EXISTS jlpt",
+	// level is 5 for N5 down to 1 for N1
+	"CREATE TABLE jlpt (id INTEGER PRIMARY KEY, fk INTEGER NOT NULL, kana TEXT NOT NULL, level INTEGER NOT NULL, UNIQUE (fk, kana))",
+}
+
+// sets the level of every reading spelled like the list entry. A word
+// listed at several levels keeps the lowest, i.e. the one with the highest
+// number.
+const jlptInsert = "INSERT INTO jlpt (fk, kana, level) " +
+	"SELECT DISTINCT r_ele.fk, r_ele.value, ? FROM r_ele " +
+	"LEFT JOIN k_ele ON r_ele.fk = k_ele.fk " +
+	"WHERE r_ele.value = ? AND (k_ele.value = ? OR r_ele.value = ?) " +
+	"ON CONFLICT (fk, kana) DO UPDATE SET level = max(level, excluded.level)"
+
+// matches a JLPT level like N5 or JLPT_N5 but not N50
+var jlptLevel = regexp.MustCompile(`(?:^|[^A-Za-z0-9])N([1-5])(?:$|[^0-9])`)
+
+// ImportJLPT reads the JLPT vocabulary list at path, which may be gzip
+// compressed, and replaces the JLPT levels with it. Every line holds a
+// word, its reading (empty for kana words) and its level like N5,
+// separated by tabs, or by commas if the file name ends in .csv. The level
+// is taken from the first of the further fields containing one, so lines
+// like "食べる,たべる,to eat,JLPT_N5" work as well. Lines without a level,
+// e.g. headers, are skipped. The levels are added to the JMdict entries
+// with the same spelling, so JMdict has to be imported first. progress, if
+// not nil, is called with the number of lines read so far after every
+// batch. It returns the number of readings with a level.
+func ImportJLPT(path string, progress func(int)) (int, error) {
+	r, err := openImport(path)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	for _, stmt := range jlptSchema {
+		if _, err := database.Exec(stmt); err != nil {
+			return 0, err
+		}
+	}
+
+	cr := csv.NewReader(r)
+	cr.Comma = '\t'
+	if strings.HasSuffix(strings.TrimSuffix(path, ".gz"), ".csv") {
+		cr.Comma = ','
+	}
+	cr.FieldsPerRecord = -1
+	cr.LazyQuotes = true
+
+	tx, err := database.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	lines := 0
+	for {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			tx.Rollback()
+			return 0, err
+		}
+
+		if level := parseLevel(fields); level > 0 && fields[0] != "" {
+			word, kana := fields[0], fields[1]
+			if kana == "" {
+				kana = word
+			}
+			if _, err := tx.Exec(jlptInsert, level, kana, word, word); err != nil {
+				tx.Rollback()
+				return 0, err
+			}
+		}
+
+		lines++
+		if lines%importBatchSize == 0 {
+			if err := tx.Commit(); err != nil {
+				return 0, err
+			}
+			if progress != nil {
+				progress(lines)
+			}
+			if tx, err = database.Begin(); err != nil {
+				return 0, err
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	rows, err := db_query("SELECT COUNT(*) FROM jlpt")
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var n int
+	if rows.Next() {
+		err = rows.Scan(&n)
+	}
+	if err != nil {
+		return 0, err
+	}
+	return n, rows.Err()
+}
+
+// parseLevel returns the JLPT level in the fields after the word and its
+// reading, 0 if there is none.
+func parseLevel(fields []string) int {
+	if len(fields) < 3 {
+		return 0
+	}
+
+	for _, f := range fields[2:] {
+		if m := jlptLevel.FindStringSubmatch(f); m != nil {
+			n, _ := strconv.Atoi(m[1])
+			return n
+		}
+	}
+	return 0
+}
@@ -0,0 +1,329 @@
+package dict
+
+import (
+	"compress/gzip"
+	"database/sql"
+	"encoding/xml"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const (
+	// number of entries written per transaction while importing
+	importBatchSize = 2000
+)
+
+var jmdictSchema = []string{
+	"DROP TABLE IF EXISTS entity",
+	"DROP TABLE IF EXISTS r_ele",
+	"DROP TABLE IF EXISTS re_restr",
+	"DROP TABLE IF EXISTS k_ele",
+	"DROP TABLE IF EXISTS sense",
+	"DROP TABLE IF EXISTS gloss",
+	"DROP TABLE IF EXISTS pos",
+	"DROP TABLE IF EXISTS misc",
+	"DROP TABLE IF EXISTS re_pri",
+	"DROP TABLE IF EXISTS ke_pri",
+	"DROP TABLE IF EXISTS ke_inf",
+	"CREATE TABLE entity (id INTEGER PRIMARY KEY, entity TEXT UNIQUE NOT NULL, description TEXT)",
+	"CREATE TABLE r_ele (id INTEGER PRIMARY KEY, fk INTEGER NOT NULL, value TEXT NOT NULL, nokanji INTEGER NOT NULL)",
+	// the kanji spellings a reading is restricted to, fk is the r_ele id
+	"CREATE TABLE re_restr (id INTEGER PRIMARY KEY, fk INTEGER NOT NULL, value TEXT NOT NULL)",
+	"CREATE TABLE k_ele (id INTEGER PRIMARY KEY, fk INTEGER NOT NULL, value TEXT NOT NULL)",
+	"CREATE TABLE sense (id INTEGER PRIMARY KEY, fk INTEGER NOT NULL)",
+	"CREATE TABLE gloss (id INTEGER PRIMARY KEY, fk INTEGER NOT NULL, value TEXT NOT NULL)",
+	"CREATE TABLE pos (id INTEGER PRIMARY KEY, fk INTEGER NOT NULL, entity INTEGER NOT NULL)",
+	// the misc tags of a sense like arch or obsc, fk is the sense id
+	"CREATE TABLE misc (id INTEGER PRIMARY KEY, fk INTEGER NOT NULL, entity INTEGER NOT NULL)",
+	// the priority tags like ichi1 or nf12 of a reading and a kanji
+	// spelling, fk is the r_ele and k_ele id
+	"CREATE TABLE re_pri (id INTEGER PRIMARY KEY, fk INTEGER NOT NULL, value TEXT NOT NULL)",
+	"CREATE TABLE ke_pri (id INTEGER PRIMARY KEY, fk INTEGER NOT NULL, value TEXT NOT NULL)",
+	// the information tags of a kanji spelling like rK, fk is the k_ele id
+	"CREATE TABLE ke_inf (id INTEGER PRIMARY KEY, fk INTEGER NOT NULL, entity INTEGER NOT NULL)",
+	"CREATE INDEX r_ele_fk ON r_ele (fk)",
+	"CREATE INDEX r_ele_value ON r_ele (value)",
+	"CREATE INDEX re_restr_fk ON re_restr (fk)",
+	"CREATE INDEX k_ele_fk ON k_ele (fk)",
+	"CREATE INDEX k_ele_value ON k_ele (value)",
+	"CREATE INDEX sense_fk ON sense (fk)",
+	"CREATE INDEX gloss_fk ON gloss (fk)",
+	"CREATE INDEX pos_fk ON pos (fk)",
+	"CREATE INDEX misc_fk ON misc (fk)",
+	"CREATE INDEX re_pri_fk ON re_pri (fk)",
+	"CREATE INDEX ke_pri_fk ON ke_pri (fk)",
+	"CREATE INDEX ke_inf_fk ON ke_inf (fk)",
+}
+
+var entityDecl = regexp.MustCompile(`<!ENTITY\s+(\S+)\s+"([^"]*)"\s*>`)
+
+type jmGloss struct {
+	Lang  string `xml:"http://www.w3.org/XML/1998/namespace lang,attr"`
+	Value string `xml:",chardata"`
+}
+
+type jmSense struct {
+	Pos   []string  `xml:"pos"`
+	Misc  []string  `xml:"misc"`
+	Gloss []jmGloss `xml:"gloss"`
+}
+
+type jmEntry struct {
+	Seq  int `xml:"ent_seq"`
+	KEle []struct {
+		Keb string   `xml:"keb"`
+		Inf []string `xml:"ke_inf"`
+		Pri []string `xml:"ke_pri"`
+	} `xml:"k_ele"`
+	REle []struct {
+		Reb     string    `xml:"reb"`
+		NoKanji *struct{} `xml:"re_nokanji"`
+		Restr   []string  `xml:"re_restr"`
+		Pri     []string  `xml:"re_pri"`
+	} `xml:"r_ele"`
+	Sense []jmSense `xml:"sense"`
+}
+
+// jmImporter writes parsed JMdict entries into the database.
+type jmImporter struct {
+	tx       *sql.Tx
+	entities map[string]int64
+	count    int
+	progress func(int)
+}
+
+// CreateSchema (re)creates the empty dictionary tables.
+func CreateSchema() error {
+	for _, stmt := range jmdictSchema {
+		if _, err := database.Exec(stmt); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportJMdict reads the JMdict file at path, which may be gzip
+// compressed, and replaces the dictionary tables with its contents.
+// progress, if not nil, is called with the number of entries imported so
+// far after every batch. It returns the number of imported entries.
+func ImportJMdict(path string, progress func(int)) (int, error) {
+	r, err := openImport(path)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	if err := CreateSchema(); err != nil {
+		return 0, err
+	}
+
+	imp := &jmImporter{entities: make(map[string]int64), progress: progress}
+	if err := imp.begin(); err != nil {
+		return 0, err
+	}
+
+	d := xml.NewDecoder(r)
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			imp.tx.Rollback()
+			return imp.count, err
+		}
+
+		switch t := t.(type) {
+		case xml.Directive:
+			// the DOCTYPE carries the entity definitions used for the pos
+			// tags. Map every entity onto its own name so that &v1; is
+			// decoded as "v1" and keep the description for the entity table.
+			d.Entity = make(map[string]string)
+			for _, m := range entityDecl.FindAllStringSubmatch(string(t), -1) {
+				d.Entity[m[1]] = m[1]
+				if err := imp.addEntity(m[1], m[2]); err != nil {
+					imp.tx.Rollback()
+					return imp.count, err
+				}
+			}
+		case xml.StartElement:
+			if t.Name.Local != "entry" {
+				continue
+			}
+
+			var e jmEntry
+			if err := d.DecodeElement(&e, &t); err != nil {
+				imp.tx.Rollback()
+				return imp.count, err
+			}
+			if err := imp.addEntry(&e); err != nil {
+				imp.tx.Rollback()
+				return imp.count, err
+			}
+		}
+	}
+
+	return imp.count, imp.tx.Commit()
+}
+
+// importFile is an import file that is decompressed while reading if its
+// name ends in .gz.
+type importFile struct {
+	io.Reader
+	f  *os.File
+	gz *gzip.Reader
+}
+
+func openImport(path string) (*importFile, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	imp := &importFile{Reader: f, f: f}
+	if strings.HasSuffix(path, ".gz") {
+		if imp.gz, err = gzip.NewReader(f); err != nil {
+			f.Close()
+			return nil, err
+		}
+		imp.Reader = imp.gz
+	}
+	return imp, nil
+}
+
+func (imp *importFile) Close() error {
+	if imp.gz != nil {
+		imp.gz.Close()
+	}
+	return imp.f.Close()
+}
+
+func (imp *jmImporter) begin() error {
+	var err error
+	imp.tx, err = database.Begin()
+	return err
+}
+
+func (imp *jmImporter) addEntity(name string, desc string) error {
+	res, err := imp.tx.Exec("INSERT INTO entity (entity, description) VALUES (?, ?)", name, desc)
+	if err != nil {
+		return err
+	}
+
+	imp.entities[name], err = res.LastInsertId()
+	return err
+}
+
+// addTags adds the values to table, one of the tables holding plain text
+// values of the element fk.
+func (imp *jmImporter) addTags(table string, fk int64, values []string) error {
+	for _, v := range values {
+		if _, err := imp.tx.Exec("INSERT INTO "+table+" (fk, value) VALUES (?, ?)", fk, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addEntities adds the entities names to table, one of the tables
+// referencing entities of the element fk.
+func (imp *jmImporter) addEntities(table string, fk int64, names []string) error {
+	for _, name := range names {
+		eid, ok := imp.entities[name]
+		if !ok {
+			if err := imp.addEntity(name, ""); err != nil {
+				return err
+			}
+			eid = imp.entities[name]
+		}
+		if _, err := imp.tx.Exec("INSERT INTO "+table+" (fk, entity) VALUES (?, ?)", fk, eid); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (imp *jmImporter) addEntry(e *jmEntry) error {
+	for _, k := range e.KEle {
+		res, err := imp.tx.Exec("INSERT INTO k_ele (fk, value) VALUES (?, ?)", e.Seq, k.Keb)
+		if err != nil {
+			return err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		if err := imp.addTags("ke_pri", id, k.Pri); err != nil {
+			return err
+		}
+		if err := imp.addEntities("ke_inf", id, k.Inf); err != nil {
+			return err
+		}
+	}
+
+	for _, r := range e.REle {
+		res, err := imp.tx.Exec("INSERT INTO r_ele (fk, value, nokanji) VALUES (?, ?, ?)", e.Seq, r.Reb, r.NoKanji != nil)
+		if err != nil {
+			return err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		if err := imp.addTags("re_restr", id, r.Restr); err != nil {
+			return err
+		}
+		if err := imp.addTags("re_pri", id, r.Pri); err != nil {
+			return err
+		}
+	}
+
+	// a sense without pos elements inherits the ones of the previous sense
+	var pos []string
+	for _, s := range e.Sense {
+		if len(s.Pos) > 0 {
+			pos = s.Pos
+		}
+
+		res, err := imp.tx.Exec("INSERT INTO sense (fk) VALUES (?)", e.Seq)
+		if err != nil {
+			return err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		for _, g := range s.Gloss {
+			if g.Lang != "" && g.Lang != "eng" {
+				continue
+			}
+			if _, err := imp.tx.Exec("INSERT INTO gloss (fk, value) VALUES (?, ?)", id, g.Value); err != nil {
+				return err
+			}
+		}
+
+		if err := imp.addEntities("pos", id, pos); err != nil {
+			return err
+		}
+		if err := imp.addEntities("misc", id, s.Misc); err != nil {
+			return err
+		}
+	}
+
+	imp.count++
+	if imp.count%importBatchSize == 0 {
+		if err := imp.tx.Commit(); err != nil {
+			return err
+		}
+		if imp.progress != nil {
+			imp.progress(imp.count)
+		}
+		return imp.begin()
+	}
+
+	return nil
+}
@@ -0,0 +1,234 @@
+package dict
+
+import (
+	"database/sql"
+	"encoding/xml"
+	"io"
+)
+
+var kanjidicSchema = []string{
+	"DROP TABLE IF EXISTS kanji",
+	"DROP TABLE IF EXISTS kanji_reading",
+	"DROP TABLE IF EXISTS kanji_meaning",
+	"CREATE TABLE kanji (literal TEXT PRIMARY KEY, strokes INTEGER NOT NULL, grade INTEGER NOT NULL, " +
+		"jlpt INTEGER NOT NULL, freq INTEGER NOT NULL)",
+	// type is one of ja_on, ja_kun or nanori
+	"CREATE TABLE kanji_reading (id INTEGER PRIMARY KEY, fk TEXT NOT NULL, type TEXT NOT NULL, value TEXT NOT NULL)",
+	"CREATE TABLE kanji_meaning (id INTEGER PRIMARY KEY, fk TEXT NOT NULL, value TEXT NOT NULL)",
+	"CREATE INDEX kanji_reading_fk ON kanji_reading (fk)",
+	"CREATE INDEX kanji_meaning_fk ON kanji_meaning (fk)",
+}
+
+type kdReading struct {
+	Type  string `xml:"r_type,attr"`
+	Value string `xml:",chardata"`
+}
+
+type kdMeaning struct {
+	Lang  string `xml:"m_lang,attr"`
+	Value string `xml:",chardata"`
+}
+
+type kdCharacter struct {
+	Literal string `xml:"literal"`
+	Misc    struct {
+		Grade   int   `xml:"grade"`
+		Strokes []int `xml:"stroke_count"`
+		Freq    int   `xml:"freq"`
+		JLPT    int   `xml:"jlpt"`
+	} `xml:"misc"`
+	RMGroup []struct {
+		Reading []kdReading `xml:"reading"`
+		Meaning []kdMeaning `xml:"meaning"`
+	} `xml:"reading_meaning>rmgroup"`
+	Nanori []string `xml:"reading_meaning>nanori"`
+}
+
+// Kanji is a KANJIDIC2 character. Grade, JLPT and Freq are 0 if unknown.
+// JLPT is the level of the old four level test, 4 being the easiest.
+// Freq is the rank among the 2500 most used kanji in newspapers.
+type Kanji struct {
+	Literal  string
+	On       []string
+	Kun      []string
+	Nanori   []string
+	Meanings []string
+	Strokes  int
+	Grade    int
+	JLPT     int
+	Freq     int
+}
+
+// ImportKanjidic reads the KANJIDIC2 XML file at path, which may be gzip
+// compressed, and replaces the kanji tables with its contents. Only the
+// japanese readings and english meanings are kept. progress, if not nil,
+// is called with the number of characters imported so far after every
+// batch. It returns the number of imported characters.
+func ImportKanjidic(path string, progress func(int)) (int, error) {
+	r, err := openImport(path)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	for _, stmt := range kanjidicSchema {
+		if _, err := database.Exec(stmt); err != nil {
+			return 0, err
+		}
+	}
+
+	tx, err := database.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	d := xml.NewDecoder(r)
+	for {
+		t, err := d.Token()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			tx.Rollback()
+			return count, err
+		}
+
+		start, ok := t.(xml.StartElement)
+		if !ok || start.Name.Local != "character" {
+			continue
+		}
+
+		var c kdCharacter
+		if err := d.DecodeElement(&c, &start); err != nil {
+			tx.Rollback()
+			return count, err
+		}
+		if err := addKanji(tx, &c); err != nil {
+			tx.Rollback()
+			return count, err
+		}
+
+		count++
+		if count%importBatchSize == 0 {
+			if err := tx.Commit(); err != nil {
+				return count, err
+			}
+			if progress != nil {
+				progress(count)
+			}
+			if tx, err = database.Begin(); err != nil {
+				return count, err
+			}
+		}
+	}
+
+	return count, tx.Commit()
+}
+
+func addKanji(tx *sql.Tx, c *kdCharacter) error {
+	// the first stroke count is the accepted one, the others are common
+	// miscounts
+	strokes := 0
+	if len(c.Misc.Strokes) > 0 {
+		strokes = c.Misc.Strokes[0]
+	}
+
+	_, err := tx.Exec("INSERT INTO kanji (literal, strokes, grade, jlpt, freq) VALUES (?, ?, ?, ?, ?)",
+		c.Literal, strokes, c.Misc.Grade, c.Misc.JLPT, c.Misc.Freq)
+	if err != nil {
+		return err
+	}
+
+	for _, g := range c.RMGroup {
+		for _, r := range g.Reading {
+			if r.Type != "ja_on" && r.Type != "ja_kun" {
+				continue
+			}
+			if _, err := tx.Exec("INSERT INTO kanji_reading (fk, type, value) VALUES (?, ?, ?)", c.Literal, r.Type, r.Value); err != nil {
+				return err
+			}
+		}
+
+		for _, m := range g.Meaning {
+			if m.Lang != "" && m.Lang != "en" {
+				continue
+			}
+			if _, err := tx.Exec("INSERT INTO kanji_meaning (fk, value) VALUES (?, ?)", c.Literal, m.Value); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, n := range c.Nanori {
+		if _, err := tx.Exec("INSERT INTO kanji_reading (fk, type, value) VALUES (?, 'nanori', ?)", c.Literal, n); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GetKanji returns the character literal, nil if it is unknown or no
+// kanji have been imported.
+func GetKanji(literal string) (*Kanji, error) {
+	if imported, err := db_has_table("kanji"); err != nil || !imported {
+		return nil, err
+	}
+
+	k := &Kanji{Literal: literal}
+	rows, err := db_query("SELECT strokes, grade, jlpt, freq FROM kanji WHERE literal = ?", literal)
+	if err != nil {
+		return nil, err
+	}
+	found := rows.Next()
+	if found {
+		err = rows.Scan(&k.Strokes, &k.Grade, &k.JLPT, &k.Freq)
+	}
+	rows.Close()
+	if err != nil {
+		return nil, err
+	} else if err := rows.Err(); err != nil || !found {
+		return nil, err
+	}
+
+	rows, err = db_query("SELECT type, value FROM kanji_reading WHERE fk = ? ORDER BY id", literal)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var typ, value string
+		if err := rows.Scan(&typ, &value); err != nil {
+			return nil, err
+		}
+
+		switch typ {
+		case "ja_on":
+			k.On = append(k.On, value)
+		case "ja_kun":
+			k.Kun = append(k.Kun, value)
+		case "nanori":
+			k.Nanori = append(k.Nanori, value)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	meanings, err := db_query("SELECT value FROM kanji_meaning WHERE fk = ? ORDER BY id", literal)
+	if err != nil {
+		return nil, err
+	}
+	defer meanings.Close()
+
+	for meanings.Next() {
+		var value string
+		if err := meanings.Scan(&value); err != nil {
+			return nil, err
+		}
+		k.Meanings = append(k.Meanings, value)
+	}
+
+	return k, meanings.Err()
+}
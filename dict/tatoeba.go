@@ -0,0 +1,236 @@
+package dict
+
+import (
+	"bufio"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/tsurai/msyu/conjugate"
+)
+
+var tatoebaSchema = []string{
+	"DROP TABLE IF EXISTS example",
+	"DROP TABLE IF EXISTS example_word",
+	"CREATE TABLE example (id INTEGER PRIMARY KEY, japanese TEXT NOT NULL, english TEXT NOT NULL)",
+	// fk is the example and entry the JMdict entry used in it. checked is
+	// set if the example is a verified good example of the entry.
+	"CREATE TABLE example_word (id INTEGER PRIMARY KEY, fk INTEGER NOT NULL, entry INTEGER NOT NULL, checked INTEGER NOT NULL)",
+	"CREATE INDEX example_word_entry ON example_word (entry)",
+}
+
+// adds the entries written like a headword of a B line to an example. The
+// reading narrows the entries down if it is given.
+const exampleWordInsert = "INSERT INTO example_word (fk, entry, checked) " +
+	"SELECT DISTINCT ?, r_ele.fk, ? FROM r_ele " +
+	"LEFT JOIN k_ele ON r_ele.fk = k_ele.fk " +
+	"WHERE (k_ele.value = ? OR r_ele.value = ?) AND (? = '' OR r_ele.value = ?)"
+
+// a word of a B line: headword, an optional |n, reading, sense number, the
+// form used in the sentence and a trailing ~ for checked examples
+var bWord = regexp.MustCompile(`^([^|(\[{~]+)(?:\|\d+)?(?:\(([^)]*)\))?(?:\[(\d+)\])?(?:\{([^}]*)\})?(~)?$`)
+
+// Example is a japanese sentence together with its english translation.
+type Example struct {
+	Japanese string
+	English  string
+}
+
+// tatoebaIndex is a line of the Tatoeba jpn_indices file.
+type tatoebaIndex struct {
+	japanese int
+	english  int
+	bline    string
+}
+
+// ImportExamples reads the example sentences from the Tatoeba files
+// sentences.csv, jpn_indices.csv and optionally links.csv, given by their
+// paths, and replaces the example tables with them. All of them may be
+// gzip compressed. The B line of an index names the dictionary form of the
+// words used in the sentence, which are matched against the JMdict
+// entries, so JMdict has to be imported first. Links are only needed for
+// indices lacking the id of the english translation. progress, if not nil,
+// is called with the number of examples imported so far after every batch.
+// It returns the number of imported examples.
+func ImportExamples(sentences string, indices string, links string, progress func(int)) (int, error) {
+	index, err := readTatoebaIndices(indices)
+	if err != nil {
+		return 0, err
+	}
+
+	needed := make(map[int]bool)
+	untranslated := make(map[int]bool)
+	for _, i := range index {
+		needed[i.japanese] = true
+		if i.english > 0 {
+			needed[i.english] = true
+		} else {
+			untranslated[i.japanese] = true
+		}
+	}
+
+	// candidate translations of the untranslated sentences
+	translations := make(map[int][]int)
+	if links != "" && len(untranslated) > 0 {
+		err := readTatoeba(links, func(fields []string) {
+			if len(fields) < 2 {
+				return
+			}
+			from, _ := strconv.Atoi(fields[0])
+			to, _ := strconv.Atoi(fields[1])
+			if untranslated[from] {
+				translations[from] = append(translations[from], to)
+				needed[to] = true
+			}
+		})
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	texts := make(map[int]string)
+	english := make(map[int]bool)
+	err = readTatoeba(sentences, func(fields []string) {
+		if len(fields) < 3 {
+			return
+		}
+		id, _ := strconv.Atoi(fields[0])
+		if !needed[id] {
+			return
+		}
+		texts[id] = fields[2]
+		english[id] = fields[1] == "eng"
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	for _, stmt := range tatoebaSchema {
+		if _, err := database.Exec(stmt); err != nil {
+			return 0, err
+		}
+	}
+
+	tx, err := database.Begin()
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, i := range index {
+		if i.english <= 0 {
+			for _, t := range translations[i.japanese] {
+				if english[t] {
+					i.english = t
+					break
+				}
+			}
+		}
+
+		japanese, ok := texts[i.japanese]
+		if !ok || !english[i.english] {
+			continue
+		}
+
+		res, err := tx.Exec("INSERT INTO example (japanese, english) VALUES (?, ?)", japanese, texts[i.english])
+		if err != nil {
+			tx.Rollback()
+			return count, err
+		}
+		id, err := res.LastInsertId()
+		if err != nil {
+			tx.Rollback()
+			return count, err
+		}
+
+		for _, w := range strings.Fields(i.bline) {
+			m := bWord.FindStringSubmatch(w)
+			if m == nil {
+				continue
+			}
+			if _, err := tx.Exec(exampleWordInsert, id, m[5] != "", m[1], m[1], m[2], m[2]); err != nil {
+				tx.Rollback()
+				return count, err
+			}
+		}
+
+		count++
+		if count%importBatchSize == 0 {
+			if err := tx.Commit(); err != nil {
+				return count, err
+			}
+			if progress != nil {
+				progress(count)
+			}
+			if tx, err = database.Begin(); err != nil {
+				return count, err
+			}
+		}
+	}
+
+	return count, tx.Commit()
+}
+
+func readTatoebaIndices(path string) ([]tatoebaIndex, error) {
+	var index []tatoebaIndex
+
+	err := readTatoeba(path, func(fields []string) {
+		if len(fields) < 3 {
+			return
+		}
+
+		japanese, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return
+		}
+		english, _ := strconv.Atoi(fields[1])
+		index = append(index, tatoebaIndex{japanese, english, fields[2]})
+	})
+
+	return index, err
+}
+
+// readTatoeba calls line with the tab separated fields of every line of
+// the Tatoeba file at path.
+func readTatoeba(path string, line func([]string)) error {
+	r, err := openImport(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	s := bufio.NewScanner(r)
+	s.Buffer(make([]byte, 64*1024), 1024*1024)
+	for s.Scan() {
+		line(strings.Split(s.Text(), "\t"))
+	}
+	return s.Err()
+}
+
+// Examples returns up to n example sentences using the word, checked
+// examples and short sentences first. nil is returned if no examples have
+// been imported.
+func Examples(w *conjugate.Word, n int) ([]*Example, error) {
+	if imported, err := db_has_table("example"); err != nil || !imported {
+		return nil, err
+	}
+
+	rows, err := db_query("SELECT example.japanese, example.english FROM example, example_word "+
+		"WHERE example_word.entry = ? AND example_word.fk = example.id "+
+		"GROUP BY example.id ORDER BY MAX(example_word.checked) DESC, length(example.japanese), example.id LIMIT ?", w.ID, n)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var examples []*Example
+	for rows.Next() {
+		e := &Example{}
+		if err := rows.Scan(&e.Japanese, &e.English); err != nil {
+			return nil, err
+		}
+		examples = append(examples, e)
+	}
+
+	return examples, rows.Err()
+}
@@ -0,0 +1,129 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/tsurai/msyu/anki"
+	"github.com/tsurai/msyu/conjugate"
+	"github.com/tsurai/msyu/dict"
+)
+
+const ankiCSS = `.card { font-family: sans-serif; font-size: 24px; text-align: center; }
+.meaning, .derivation { font-size: 18px; }
+.form { font-size: 18px; color: #666; }`
+
+// the note types of exported decks. Their ids must never change, Anki
+// matches the notes of later exports against them.
+var (
+	vocabModel = &anki.Model{
+		ID:     1403200001,
+		Name:   "msyu Vocabulary",
+		Fields: []string{"Word", "Reading", "Furigana", "Meaning", "Class", "Pitch"},
+		Front:  "{{Word}}",
+		Back: "{{FrontSide}}<hr id=answer>{{furigana:Furigana}}<br>{{Reading}}" +
+			"{{#Pitch}}<br>{{Pitch}}{{/Pitch}}<div class=meaning>{{Meaning}}</div>",
+		CSS: ankiCSS,
+	}
+
+	conjModel = &anki.Model{
+		ID:   1403200002,
+		Name: "msyu Conjugation",
+		Fields: []string{"Word", "Reading", "Meaning", "Form", "Polarity", "Politeness",
+			"Answer", "AnswerKanji", "Derivation"},
+		Front: "{{Word}} ({{Reading}})<div class=form>{{Form}} - {{Polarity}} / {{Politeness}}</div>",
+		Back: "{{FrontSide}}<hr id=answer>{{Answer}}{{#AnswerKanji}}<br>{{furigana:AnswerKanji}}{{/AnswerKanji}}" +
+			"<div class=derivation>{{Derivation}}</div>",
+		CSS: ankiCSS,
+	}
+)
+
+// ankiTag returns s usable as Anki tag, which can't contain spaces.
+func ankiTag(s string) string {
+	return strings.Replace(s, " ", "_", -1)
+}
+
+// addVocabNote adds the vocabulary card of w to deck.
+func addVocabNote(deck *anki.Deck, w *conjugate.Word) error {
+	accents, err := dict.Accents(w)
+	if err != nil {
+		return err
+	}
+
+	word, furi := w.Kana, ""
+	if w.Kanji[0] != "" {
+		word = strings.Join(w.Kanji, ", ")
+		var spellings []string
+		for _, k := range w.Kanji {
+			spellings = append(spellings, furigana(k, w.Kana))
+		}
+		furi = strings.Join(spellings, ", ")
+	}
+
+	var meanings []string
+	for _, g := range w.Gloss {
+		meanings = append(meanings, strings.Join(g.Meaning, ", "))
+	}
+
+	pitch := ""
+	if len(accents) > 0 {
+		pitch = formatAccents(w.Kana, accents)
+	}
+
+	tags := []string{"msyu", "vocab"}
+	if w.Class() != "" {
+		tags = append(tags, w.Class())
+	}
+
+	deck.Add(vocabModel, fmt.Sprintf("vocab/%d/%s", w.ID, w.Kana),
+		[]string{word, w.Kana, furi, strings.Join(meanings, "; "), w.Class(), pitch}, tags...)
+	return nil
+}
+
+// addConjNotes adds a conjugation card to deck for every form of w in
+// forms, or every form if forms is nil, with every polarity and politeness
+// it exists with.
+func addConjNotes(deck *anki.Deck, w *conjugate.Word, forms map[string]bool) {
+	word := w.Kana
+	if w.Kanji[0] != "" {
+		word = strings.Join(w.Kanji, ", ")
+	}
+
+	meaning := ""
+	if len(w.Gloss) > 0 {
+		meaning = strings.Join(w.Gloss[0].Meaning, ", ")
+	}
+
+	for _, c := range w.Conjugations() {
+		if forms != nil && !forms[formKey(c.Name)] {
+			continue
+		}
+
+		for _, positive := range []bool{true, false} {
+			for _, polite := range []bool{false, true} {
+				kana, _ := c.Exec(w, positive, polite)
+				if kana == "" {
+					continue
+				}
+
+				polarity, politeness := "Positive", "Plain"
+				if !positive {
+					polarity = "Negative"
+				}
+				if polite {
+					politeness = "Polite"
+				}
+
+				var spellings []string
+				for _, k := range c.Kanji(w, positive, polite) {
+					spellings = append(spellings, furigana(k, kana))
+				}
+
+				key := fmt.Sprintf("conj/%d/%s/%s/%t/%t", w.ID, w.Kana, c.Name, positive, polite)
+				deck.Add(conjModel, key, []string{word, w.Kana, meaning, c.Name, polarity, politeness,
+					kana, strings.Join(spellings, ", "), explainForm(w, c.Form, positive, polite)},
+					"msyu", "conj", w.Class(), ankiTag(c.Name))
+			}
+		}
+	}
+}
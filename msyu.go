@@ -3,8 +3,11 @@ package main
 import (
 	"flag"
 	"fmt"
-	"html/template"
+	"log"
 	"os"
+	"text/template"
+
+	"github.com/tsurai/msyu/dict"
 )
 
 const (
@@ -18,22 +21,14 @@ type command struct {
 	Long      string
 }
 
-type gloss struct {
-	pos     []string
-	meaning []string
-}
-
-type word struct {
-	kana  string
-	kanji []string
-	gloss []*gloss
-}
-
 var usageTemplate = `msyu is a japanese learning tool.
 
 Usage:
 
-        mysu <command> [arguments]
+        mysu [-tui] <command> [arguments]
+
+-tui runs tests, reviews and conjugation tables in a full-screen interface
+and selects search results with the arrow keys.
 
 The commands are:
 {{range .}}
@@ -75,9 +70,14 @@ func help(args []string) {
 }
 
 func main() {
+	flag.BoolVar(&useTUI, "tui", false, "")
 	flag.Parse()
 	args := flag.Args()
 
+	if useTUI {
+		log.SetOutput(restoringWriter{})
+	}
+
 	if len(args) < 1 {
 		tmpl(usageTemplate, commands)
 
@@ -89,13 +89,15 @@ func main() {
 		os.Exit(2)
 	}
 
-	DB_init()
+	if err := dict.Init("JMdict.db"); err != nil {
+		log.Fatal("A database error has occured:", err)
+	}
 	for _, cmd := range commands {
 		if args[0] == cmd.Name() {
 			cmd.Run(&cmd, args[1:])
 		}
 	}
-	DB_close()
+	dict.Close()
 
 	return
 }
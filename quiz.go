@@ -0,0 +1,326 @@
+package main
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/tsurai/msyu/conjugate"
+	"github.com/tsurai/msyu/dict"
+)
+
+// quizItem is a single question of a test: a form of a word.
+type quizItem struct {
+	word     *conjugate.Word
+	conj     conjugate.Conjugation
+	positive bool
+	polite   bool
+}
+
+// quizOptions select the questions of a test. The zero value asks for
+// every form with equal chance, each with a random polarity and
+// politeness.
+type quizOptions struct {
+	// forms holds the keys of the forms asked for, every form if nil
+	forms map[string]bool
+	// weights holds how often a form is asked for relative to the others
+	// by key, 1 if it is missing
+	weights map[string]int
+	// positive and polite restrict the polarity and politeness if not nil
+	positive *bool
+	polite   *bool
+	// criteria select the words asked for, criteria.Pos holding the JMdict
+	// part of speech tags of their classes
+	criteria dict.Criteria
+	// choices is the number of options of multiple choice questions, 0 to
+	// ask for typed answers
+	choices int
+}
+
+// choice is an option of a multiple choice question.
+type choice struct {
+	text string
+	// mistake describes the mistake leading to a wrong option, empty for
+	// the correct one
+	mistake string
+}
+
+// formKey returns the form name in the spelling used to compare it with
+// user input: lower case without spaces, dashes or underscores, so "Te
+// Form", "te-form" and "teform" are the same.
+func formKey(name string) string {
+	name = strings.ToLower(name)
+	for _, c := range []string{" ", "-", "_"} {
+		name = strings.Replace(name, c, "", -1)
+	}
+	return name
+}
+
+// findForm returns the key of the form named name. The name may be
+// shortened like the forms of conj -chain, e.g. past for the past tense
+// or te for the te form.
+func findForm(name string) (string, error) {
+	known := make(map[string]bool)
+	for _, c := range append(conjugate.VerbConjugations, conjugate.AdjConjugations...) {
+		known[formKey(c.Name)] = true
+	}
+
+	key := formKey(name)
+	for _, k := range []string{key, key + "tense", key + "form", "te" + key} {
+		if known[k] && key != "" {
+			return k, nil
+		}
+	}
+	return "", fmt.Errorf("unknown form '%s'", strings.TrimSpace(name))
+}
+
+// parseForms returns the set of form keys named in the comma separated
+// list s, nil if s is empty.
+func parseForms(s string) (map[string]bool, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	forms := make(map[string]bool)
+	for _, name := range strings.Split(s, ",") {
+		key, err := findForm(name)
+		if err != nil {
+			return nil, err
+		}
+		forms[key] = true
+	}
+	return forms, nil
+}
+
+// parseWeights returns the form weights of a comma separated list like
+// "past=3,te=2" by form key, nil if s is empty.
+func parseWeights(s string) (map[string]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	weights := make(map[string]int)
+	for _, w := range strings.Split(s, ",") {
+		i := strings.Index(w, "=")
+		if i < 0 {
+			return nil, fmt.Errorf("invalid weight '%s'", w)
+		}
+
+		key, err := findForm(w[:i])
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(w[i+1:]))
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid weight '%s'", w)
+		}
+		weights[key] = n
+	}
+	return weights, nil
+}
+
+// parseChoice returns nil if s is empty or "both", else whether s is the
+// first of the two values.
+func parseChoice(s string, yes string, no string) (*bool, error) {
+	switch strings.ToLower(s) {
+	case "", "both":
+		return nil, nil
+	case yes:
+		b := true
+		return &b, nil
+	case no:
+		b := false
+		return &b, nil
+	}
+	return nil, fmt.Errorf("'%s' is neither %s, %s nor both", s, yes, no)
+}
+
+// newQuizOptions returns the options described by the flags of test conj.
+func newQuizOptions(forms string, polarity string, politeness string, classes string, weights string) (*quizOptions, error) {
+	o := &quizOptions{}
+
+	var err error
+	if o.forms, err = parseForms(forms); err != nil {
+		return nil, err
+	}
+	if o.weights, err = parseWeights(weights); err != nil {
+		return nil, err
+	}
+	if o.positive, err = parseChoice(polarity, "positive", "negative"); err != nil {
+		return nil, err
+	}
+	if o.polite, err = parseChoice(politeness, "polite", "plain"); err != nil {
+		return nil, err
+	}
+	if classes != "" {
+		o.criteria.Pos = strings.Split(classes, ",")
+	}
+
+	return o, nil
+}
+
+// weight returns how often the conjugation is asked for relative to the
+// others, 0 if it isn't asked for at all.
+func (o *quizOptions) weight(c conjugate.Conjugation) int {
+	key := formKey(c.Name)
+	if o.forms != nil && !o.forms[key] {
+		return 0
+	}
+	if w, ok := o.weights[key]; ok {
+		return w
+	}
+	return 1
+}
+
+// pick picks a random form of word the options ask for: first the form
+// according to the weights, then one of its polarities and politeness
+// levels. nil is returned if none of the forms of word are asked for.
+func (o *quizOptions) pick(word *conjugate.Word) (*quizItem, error) {
+	if o.criteria.Pos != nil && !hasPos(word, o.criteria.Pos) {
+		return nil, nil
+	}
+
+	var candidates [][]quizItem
+	var weights []int
+	total := 0
+	for _, c := range word.Conjugations() {
+		w := o.weight(c)
+		if w == 0 {
+			continue
+		}
+
+		var items []quizItem
+		for _, positive := range []bool{true, false} {
+			for _, polite := range []bool{false, true} {
+				if (o.positive != nil && *o.positive != positive) || (o.polite != nil && *o.polite != polite) {
+					continue
+				}
+				// forms like the ず form only exist as a plain negative
+				if c.Has(word, positive, polite) {
+					items = append(items, quizItem{word, c, positive, polite})
+				}
+			}
+		}
+
+		if len(items) > 0 {
+			candidates = append(candidates, items)
+			weights = append(weights, w)
+			total += w
+		}
+	}
+	if total == 0 {
+		return nil, nil
+	}
+
+	r, err := randomInt(total)
+	if err != nil {
+		return nil, err
+	}
+
+	i := 0
+	for r >= weights[i] {
+		r -= weights[i]
+		i++
+	}
+
+	j, err := randomInt(len(candidates[i]))
+	if err != nil {
+		return nil, err
+	}
+	return &candidates[i][j], nil
+}
+
+// choices returns the options of a multiple choice question for the item,
+// at most n, in random order. The wrong ones are the most likely mistakes
+// for the form. The options are written in kanji if all of them can be told
+// apart that way, else in kana.
+func (it *quizItem) choices(n int) ([]choice, error) {
+	kana, kanji := it.conj.Exec(it.word, it.positive, it.polite)
+	options := []conjugate.Mistake{{Kana: kana, Kanji: kanji}}
+	for _, m := range conjugate.Mistakes(it.word, it.conj, it.positive, it.polite) {
+		if len(options) == n {
+			break
+		}
+		options = append(options, m)
+	}
+
+	inKanji := true
+	seen := make(map[string]bool)
+	for _, o := range options {
+		inKanji = inKanji && o.Kanji != "" && !seen[o.Kanji]
+		seen[o.Kanji] = true
+	}
+
+	var choices []choice
+	for _, o := range options {
+		c := choice{text: o.Kana, mistake: o.Reason}
+		if inKanji {
+			c.text = o.Kanji
+		}
+		choices = append(choices, c)
+	}
+
+	for i := len(choices) - 1; i > 0; i-- {
+		j, err := randomInt(i + 1)
+		if err != nil {
+			return nil, err
+		}
+		choices[i], choices[j] = choices[j], choices[i]
+	}
+	return choices, nil
+}
+
+// quizWords returns up to n random words from source, either the whole
+// dictionary or the vocabulary unlocked on WaniKani, that have a form the
+// options ask for.
+func (o *quizOptions) quizWords(n int, source string) ([]*conjugate.Word, error) {
+	if source == "wanikani" {
+		var err error
+		words := WK_random_words(n, func(w *conjugate.Word) bool {
+			if it, _ := o.pick(w); it == nil || err != nil {
+				return false
+			}
+			var matches []*conjugate.Word
+			matches, err = dict.Filter([]*conjugate.Word{w}, o.criteria)
+			return len(matches) > 0
+		})
+		return words, err
+	}
+
+	var words []*conjugate.Word
+	seen := make(map[string]bool)
+
+	// the forms asked for may not exist for every word, so more words are
+	// drawn until there are enough
+	for tries := 0; tries < 10 && len(words) < n; tries++ {
+		batch, err := dict.RandomWordsMatching(n, dict.CONJUGABLE, o.criteria)
+		if err != nil {
+			return nil, err
+		}
+		if len(batch) == 0 {
+			break
+		}
+
+		for _, w := range batch {
+			key := strconv.Itoa(w.ID) + w.Kana
+			if it, _ := o.pick(w); it == nil || seen[key] || len(words) == n {
+				continue
+			}
+			seen[key] = true
+			words = append(words, w)
+		}
+	}
+
+	return words, nil
+}
+
+// randomInt returns a random number in [0, n).
+func randomInt(n int) (int, error) {
+	r, err := rand.Int(rand.Reader, big.NewInt(int64(n)))
+	if err != nil {
+		return 0, err
+	}
+	return int(r.Int64()), nil
+}
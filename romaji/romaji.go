@@ -0,0 +1,290 @@
+// Package romaji transliterates between kana and the Hepburn and Kunrei
+// romanizations of japanese.
+package romaji
+
+import (
+	"strings"
+	"unicode"
+)
+
+// System is a romanization system.
+type System int
+
+const (
+	Hepburn System = iota
+	Kunrei
+)
+
+// kana as romanized by Kunrei-shiki. Hepburn spellings that differ are
+// listed in hepburn.
+var kunrei = map[string]string{
+	"あ": "a", "い": "i", "う": "u", "え": "e", "お": "o",
+	"か": "ka", "き": "ki", "く": "ku", "け": "ke", "こ": "ko",
+	"が": "ga", "ぎ": "gi", "ぐ": "gu", "げ": "ge", "ご": "go",
+	"さ": "sa", "し": "si", "す": "su", "せ": "se", "そ": "so",
+	"ざ": "za", "じ": "zi", "ず": "zu", "ぜ": "ze", "ぞ": "zo",
+	"た": "ta", "ち": "ti", "つ": "tu", "て": "te", "と": "to",
+	"だ": "da", "ぢ": "zi", "づ": "zu", "で": "de", "ど": "do",
+	"な": "na", "に": "ni", "ぬ": "nu", "ね": "ne", "の": "no",
+	"は": "ha", "ひ": "hi", "ふ": "hu", "へ": "he", "ほ": "ho",
+	"ば": "ba", "び": "bi", "ぶ": "bu", "べ": "be", "ぼ": "bo",
+	"ぱ": "pa", "ぴ": "pi", "ぷ": "pu", "ぺ": "pe", "ぽ": "po",
+	"ま": "ma", "み": "mi", "む": "mu", "め": "me", "も": "mo",
+	"や": "ya", "ゆ": "yu", "よ": "yo",
+	"ら": "ra", "り": "ri", "る": "ru", "れ": "re", "ろ": "ro",
+	"わ": "wa", "を": "o", "ゔ": "vu",
+	"ぁ": "xa", "ぃ": "xi", "ぅ": "xu", "ぇ": "xe", "ぉ": "xo",
+	"ゃ": "xya", "ゅ": "xyu", "ょ": "xyo",
+	"きゃ": "kya", "きゅ": "kyu", "きょ": "kyo",
+	"ぎゃ": "gya", "ぎゅ": "gyu", "ぎょ": "gyo",
+	"しゃ": "sya", "しゅ": "syu", "しょ": "syo",
+	"じゃ": "zya", "じゅ": "zyu", "じょ": "zyo",
+	"ちゃ": "tya", "ちゅ": "tyu", "ちょ": "tyo",
+	"ぢゃ": "zya", "ぢゅ": "zyu", "ぢょ": "zyo",
+	"にゃ": "nya", "にゅ": "nyu", "にょ": "nyo",
+	"ひゃ": "hya", "ひゅ": "hyu", "ひょ": "hyo",
+	"びゃ": "bya", "びゅ": "byu", "びょ": "byo",
+	"ぴゃ": "pya", "ぴゅ": "pyu", "ぴょ": "pyo",
+	"みゃ": "mya", "みゅ": "myu", "みょ": "myo",
+	"りゃ": "rya", "りゅ": "ryu", "りょ": "ryo",
+	"しぇ": "sye", "じぇ": "zye", "ちぇ": "tye",
+	"ふぁ": "fa", "ふぃ": "fi", "ふぇ": "fe", "ふぉ": "fo",
+	"てぃ": "ti", "でぃ": "di", "とぅ": "tu", "どぅ": "du",
+}
+
+var hepburn = map[string]string{
+	"し": "shi", "じ": "ji", "ち": "chi", "ぢ": "ji", "つ": "tsu", "ふ": "fu",
+	"しゃ": "sha", "しゅ": "shu", "しょ": "sho",
+	"じゃ": "ja", "じゅ": "ju", "じょ": "jo",
+	"ちゃ": "cha", "ちゅ": "chu", "ちょ": "cho",
+	"ぢゃ": "ja", "ぢゅ": "ju", "ぢょ": "jo",
+	"しぇ": "she", "じぇ": "je", "ちぇ": "che",
+}
+
+// romanizations only accepted as input, on top of both systems
+var input = map[string]string{
+	"wo": "を", "di": "ぢ", "du": "づ", "dzu": "づ",
+	"jya": "じゃ", "jyu": "じゅ", "jyo": "じょ",
+	"cya": "ちゃ", "cyu": "ちゅ", "cyo": "ちょ",
+	"la": "ぁ", "li": "ぃ", "lu": "ぅ", "le": "ぇ", "lo": "ぉ",
+	"lya": "ゃ", "lyu": "ゅ", "lyo": "ょ",
+	"xtu": "っ", "ltu": "っ", "xtsu": "っ", "ltsu": "っ",
+}
+
+var toKana = make(map[string]string)
+
+// long vowels written with a macron (Hepburn) or circumflex (Kunrei)
+var longVowels = map[rune]string{
+	'ā': "aa", 'ī': "ii", 'ū': "uu", 'ē': "ee", 'ō': "ou",
+	'â': "aa", 'î': "ii", 'û': "uu", 'ê': "ee", 'ô': "ou",
+}
+
+var macrons = map[byte]string{'a': "ā", 'i': "ī", 'u': "ū", 'e': "ē", 'o': "ō"}
+var circumflexes = map[byte]string{'a': "â", 'i': "î", 'u': "û", 'e': "ê", 'o': "ô"}
+
+func init() {
+	for k, r := range kunrei {
+		if _, ok := toKana[r]; !ok || len(k) < len(toKana[r]) {
+			toKana[r] = k
+		}
+	}
+	for k, r := range hepburn {
+		if _, ok := toKana[r]; !ok || len(k) < len(toKana[r]) {
+			toKana[r] = k
+		}
+	}
+	for r, k := range input {
+		toKana[r] = k
+	}
+
+	// ambiguous spellings resolve to the common kana
+	toKana["zi"] = "じ"
+	toKana["zu"] = "ず"
+	toKana["ji"] = "じ"
+	toKana["ti"] = "ち"
+	toKana["tu"] = "つ"
+	toKana["o"] = "お"
+	toKana["zya"] = "じゃ"
+	toKana["zyu"] = "じゅ"
+	toKana["zyo"] = "じょ"
+	toKana["ja"] = "じゃ"
+	toKana["ju"] = "じゅ"
+	toKana["jo"] = "じょ"
+}
+
+func isVowel(c byte) bool {
+	return c == 'a' || c == 'i' || c == 'u' || c == 'e' || c == 'o'
+}
+
+// ToKana transliterates romaji written in Hepburn, Kunrei or the usual
+// input method spellings to hiragana. ok is false if s contains anything
+// that isn't romaji.
+func ToKana(s string) (kana string, ok bool) {
+	var out strings.Builder
+	var in strings.Builder
+
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		if l, ok := longVowels[r]; ok {
+			in.WriteString(l)
+		} else {
+			in.WriteRune(r)
+		}
+	}
+
+	b := in.String()
+	if b == "" {
+		return "", false
+	}
+
+	for i := 0; i < len(b); {
+		c := b[i]
+		var next byte
+		if i+1 < len(b) {
+			next = b[i+1]
+		}
+
+		switch {
+		case c == '-':
+			out.WriteString("ー")
+			i++
+			continue
+		case c == '\'':
+			i++
+			continue
+		case c == 'n' && next == '\'':
+			out.WriteString("ん")
+			i += 2
+			continue
+		case c == 'n' && next == 'n':
+			// nn is ん, unless the second n starts the next syllable
+			out.WriteString("ん")
+			if i+2 < len(b) && (isVowel(b[i+2]) || b[i+2] == 'y') {
+				i++
+			} else {
+				i += 2
+			}
+			continue
+		case c == 'n' && !isVowel(next) && next != 'y':
+			out.WriteString("ん")
+			i++
+			continue
+		case c == 'm' && (next == 'b' || next == 'm' || next == 'p'):
+			// Hepburn writes ん before labials as m: shimbun
+			out.WriteString("ん")
+			i++
+			continue
+		case c == 't' && next == 'c' && i+2 < len(b) && b[i+2] == 'h':
+			out.WriteString("っ")
+			i++
+			continue
+		case c == next && !isVowel(c) && c >= 'a' && c <= 'z':
+			out.WriteString("っ")
+			i++
+			continue
+		}
+
+		matched := false
+		for l := 4; l > 0; l-- {
+			if i+l > len(b) {
+				continue
+			}
+			if k, ok := toKana[b[i:i+l]]; ok {
+				out.WriteString(k)
+				i += l
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			return out.String(), false
+		}
+	}
+
+	return out.String(), true
+}
+
+// FromKana romanizes the hiragana and katakana in s using the given
+// system. Long vowels are only marked where a katakana ー makes them
+// explicit, everything else that isn't kana is copied unchanged.
+func FromKana(s string, system System) string {
+	var out strings.Builder
+	runes := []rune(toHiragana(s))
+
+	syllable := func(i int) (string, int) {
+		if i+1 < len(runes) {
+			k := string(runes[i : i+2])
+			if r, ok := lookup(k, system); ok {
+				return r, 2
+			}
+		}
+		if r, ok := lookup(string(runes[i]), system); ok {
+			return r, 1
+		}
+		return string(runes[i]), 1
+	}
+
+	for i := 0; i < len(runes); {
+		switch runes[i] {
+		case 'っ':
+			if i+1 < len(runes) {
+				r, _ := syllable(i + 1)
+				if system == Hepburn && strings.HasPrefix(r, "ch") {
+					out.WriteString("t")
+				} else if r != "" && !isVowel(r[0]) {
+					out.WriteByte(r[0])
+				}
+			}
+			i++
+		case 'ん':
+			out.WriteString("n")
+			if i+1 < len(runes) {
+				r, _ := syllable(i + 1)
+				if r != "" && (isVowel(r[0]) || r[0] == 'y') {
+					out.WriteString("'")
+				}
+			}
+			i++
+		case 'ー':
+			str := out.String()
+			if str != "" && isVowel(str[len(str)-1]) {
+				marks := macrons
+				if system == Kunrei {
+					marks = circumflexes
+				}
+				out.Reset()
+				out.WriteString(str[:len(str)-1] + marks[str[len(str)-1]])
+			} else {
+				out.WriteString("-")
+			}
+			i++
+		default:
+			r, n := syllable(i)
+			out.WriteString(r)
+			i += n
+		}
+	}
+
+	return out.String()
+}
+
+func lookup(kana string, system System) (string, bool) {
+	if system == Hepburn {
+		if r, ok := hepburn[kana]; ok {
+			return r, true
+		}
+	}
+	r, ok := kunrei[kana]
+	return r, ok
+}
+
+// toHiragana converts the katakana in s to hiragana. The long vowel mark
+// ー is kept.
+func toHiragana(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.Is(unicode.Katakana, r) && r >= 'ァ' && r <= 'ヶ' {
+			return r - ('ァ' - 'ぁ')
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,77 @@
+package romaji
+
+import (
+	"testing"
+)
+
+func TestToKana(t *testing.T) {
+	tests := []struct {
+		romaji string
+		kana   string
+		ok     bool
+	}{
+		{"tabemasen", "たべません", true},
+		{"TABEru", "たべる", true},
+		{"gakkō", "がっこう", true},
+		{"gakkou", "がっこう", true},
+		{"tôkyô", "とうきょう", true},
+		{"matcha", "まっちゃ", true},
+		{"kitte", "きって", true},
+		{"kon'nichiwa", "こんにちわ", true},
+		{"konnichiwa", "こんにちわ", true},
+		{"shimbun", "しんぶん", true},
+		{"sanpo", "さんぽ", true},
+		{"hon'ya", "ほんや", true},
+		{"honya", "ほにゃ", true},
+		{"hon", "ほん", true},
+		{"onna", "おんな", true},
+		{"sinbun", "しんぶん", true},
+		{"tyotto", "ちょっと", true},
+		{"chotto", "ちょっと", true},
+		{"tsukau", "つかう", true},
+		{"fuji", "ふじ", true},
+		{"ra-men", "らーめん", true},
+		{"", "", false},
+		{"eat!", "", false},
+		{"salary)", "", false},
+	}
+
+	for _, tt := range tests {
+		kana, ok := ToKana(tt.romaji)
+		if ok != tt.ok || (ok && kana != tt.kana) {
+			t.Errorf("%q: got %s, %v, want %s, %v", tt.romaji, kana, ok, tt.kana, tt.ok)
+		}
+	}
+}
+
+func TestFromKana(t *testing.T) {
+	tests := []struct {
+		kana    string
+		hepburn string
+		kunrei  string
+	}{
+		{"たべません", "tabemasen", "tabemasen"},
+		{"がっこう", "gakkou", "gakkou"},
+		{"まっちゃ", "matcha", "mattya"},
+		{"ちょっと", "chotto", "tyotto"},
+		{"こんにちは", "konnichiha", "konnitiha"},
+		{"きんえん", "kin'en", "kin'en"},
+		{"ほんや", "hon'ya", "hon'ya"},
+		{"しんぶん", "shinbun", "sinbun"},
+		{"つかう", "tsukau", "tukau"},
+		{"ふじ", "fuji", "huzi"},
+		{"ラーメン", "rāmen", "râmen"},
+		{"コーヒー", "kōhī", "kôhî"},
+		{"カタカナ", "katakana", "katakana"},
+		{"食べる", "食beru", "食beru"},
+	}
+
+	for _, tt := range tests {
+		if got := FromKana(tt.kana, Hepburn); got != tt.hepburn {
+			t.Errorf("%s (Hepburn): got %s, want %s", tt.kana, got, tt.hepburn)
+		}
+		if got := FromKana(tt.kana, Kunrei); got != tt.kunrei {
+			t.Errorf("%s (Kunrei): got %s, want %s", tt.kana, got, tt.kunrei)
+		}
+	}
+}
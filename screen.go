@@ -0,0 +1,398 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/tsurai/msyu/conjugate"
+	"github.com/tsurai/msyu/tui"
+)
+
+// useTUI is set by the -tui flag and selects the full-screen interface
+// for tests, conjugation tables and the selection of search results.
+var useTUI = false
+
+// quizScreen is the full-screen terminal while a test or review runs in
+// it, nil otherwise.
+var quizScreen *tui.Terminal
+
+// restoringWriter restores the terminal before anything is written to
+// it, so messages of log.Fatal aren't lost on the alternate screen.
+type restoringWriter struct{}
+
+func (restoringWriter) Write(p []byte) (int, error) {
+	tui.Restore()
+	return os.Stderr.Write(p)
+}
+
+// openScreen switches to the full-screen interface if it has been asked
+// for. It returns nil if it hasn't or stdin isn't a terminal, in which case
+// the line based interface is used.
+func openScreen() *tui.Terminal {
+	if !useTUI {
+		return nil
+	}
+
+	t, err := tui.Open()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "Can't use the full-screen interface:", err)
+		useTUI = false
+		return nil
+	}
+	return t
+}
+
+// interrupt leaves the full-screen interface and exits like an
+// interrupted program.
+func interrupt(t *tui.Terminal) {
+	t.Close()
+	os.Exit(130)
+}
+
+// textLines returns s as unstyled lines wrapped at width.
+func textLines(s string, width int) []tui.Line {
+	var lines []tui.Line
+	for _, l := range tui.Wrap(strings.TrimRight(s, "\n"), width) {
+		lines = append(lines, tui.Line{Text: l})
+	}
+	return lines
+}
+
+// pager shows the lines built by content for the current width, which can
+// be scrolled with the arrow and page keys, below title and above the
+// help line. Lines too long for the screen are scrolled with ←/→. It returns the key that closed it, one of keys. q counts as
+// Esc.
+func pager(t *tui.Terminal, title tui.Line, content func(width int) []tui.Line, help string, keys ...tui.Key) tui.Event {
+	offset, column := 0, 0
+	for {
+		width, height := t.Size()
+		lines := content(width)
+		// the title, a blank line and the help line take up 3 lines
+		page := height - 3
+		if page < 1 {
+			page = 1
+		}
+		if offset > len(lines)-page {
+			offset = len(lines) - page
+		}
+		if offset < 0 {
+			offset = 0
+		}
+		widest := 0
+		for _, l := range lines {
+			if w := tui.Width(l.Text); w > widest {
+				widest = w
+			}
+		}
+		if column > widest-width {
+			column = widest - width
+		}
+		if column < 0 {
+			column = 0
+		}
+
+		end := offset + page
+		if end > len(lines) {
+			end = len(lines)
+		}
+		screen := []tui.Line{title, {}}
+		for _, l := range lines[offset:end] {
+			screen = append(screen, tui.Line{Text: tui.Skip(l.Text, column), Style: l.Style})
+		}
+		for len(screen) < height-1 {
+			screen = append(screen, tui.Line{})
+		}
+		status := help
+		if len(lines) > page {
+			status = fmt.Sprintf("%s  ↑/↓ scroll (%d-%d of %d)", help, offset+1, end, len(lines))
+		}
+		t.Draw(append(screen, tui.Line{Text: status, Style: tui.Dim}), 0, -1)
+
+		ev, ok := <-t.Events()
+		if !ok {
+			return tui.Event{Key: tui.KeyEsc}
+		}
+		switch ev.Key {
+		case tui.KeyUp:
+			offset--
+		case tui.KeyDown:
+			offset++
+		case tui.KeyLeft:
+			column -= width / 2
+		case tui.KeyRight:
+			column += width / 2
+		case tui.KeyPageUp:
+			offset -= page
+		case tui.KeyPageDown:
+			offset += page
+		case tui.KeyRune:
+			if ev.Rune == ' ' {
+				offset += page
+			}
+		case tui.KeyHome:
+			offset = 0
+		case tui.KeyEnd:
+			offset = len(lines)
+		case tui.KeyCtrlC:
+			interrupt(t)
+		}
+
+		for _, k := range keys {
+			if ev.Key == k || (k == tui.KeyEsc && ev.Key == tui.KeyRune && ev.Rune == 'q') {
+				return ev
+			}
+		}
+	}
+}
+
+// selectScreen lets the user choose one of words with the arrow keys. It
+// returns nil if the selection is cancelled.
+func selectScreen(t *tui.Terminal, words []*conjugate.Word) *conjugate.Word {
+	entries := make([][]string, len(words))
+	for i, w := range words {
+		entries[i] = wordLines(w)
+	}
+
+	selected, offset := 0, 0
+	for {
+		width, height := t.Size()
+		page := height - 3
+
+		// scroll so that every line of the selected entry is visible
+		var lines []tui.Line
+		top, bottom := 0, 0
+		for i, e := range entries {
+			if i == selected {
+				top = len(lines)
+			}
+			for j, l := range e {
+				line := tui.Line{Text: l}
+				if i == selected {
+					line.Style = tui.Bold
+					if j == 0 {
+						line.Style = tui.Reverse
+						line.Text = tui.Truncate(l+strings.Repeat(" ", width), width)
+					}
+				}
+				lines = append(lines, line)
+			}
+			if i == selected {
+				bottom = len(lines)
+			}
+		}
+		if top < offset {
+			offset = top
+		} else if bottom > offset+page {
+			offset = bottom - page
+			if offset > top {
+				offset = top
+			}
+		}
+
+		end := offset + page
+		if end > len(lines) {
+			end = len(lines)
+		}
+		screen := []tui.Line{{Text: fmt.Sprintf("Select an entry (%d of %d)", selected+1, len(words)), Style: tui.Bold}, {}}
+		screen = append(screen, lines[offset:end]...)
+		for len(screen) < height-1 {
+			screen = append(screen, tui.Line{})
+		}
+		help := "↑/↓ select  PgUp/PgDn page  Enter choose  Esc cancel"
+		t.Draw(append(screen, tui.Line{Text: help, Style: tui.Dim}), 0, -1)
+
+		ev, ok := <-t.Events()
+		if !ok {
+			return nil
+		}
+		switch ev.Key {
+		case tui.KeyUp:
+			selected--
+		case tui.KeyDown, tui.KeyTab:
+			selected++
+		case tui.KeyPageUp:
+			selected -= 5
+		case tui.KeyPageDown:
+			selected += 5
+		case tui.KeyHome:
+			selected = 0
+		case tui.KeyEnd:
+			selected = len(words) - 1
+		case tui.KeyEnter:
+			return words[selected]
+		case tui.KeyEsc:
+			return nil
+		case tui.KeyCtrlC:
+			interrupt(t)
+		case tui.KeyRune:
+			if ev.Rune == 'q' {
+				return nil
+			}
+		}
+
+		if selected < 0 {
+			selected = 0
+		} else if selected >= len(words) {
+			selected = len(words) - 1
+		}
+	}
+}
+
+// askScreen asks for the given conjugation of word in the full-screen
+// interface and tells the user whether the answer was correct. Esc ends
+// the program.
+func askScreen(t *tui.Terminal, word *conjugate.Word, conj conjugate.Conjugation, positive bool, polite bool) bool {
+	var input tui.Editor
+
+	spelling := word.Kana
+	if word.Kanji[0] != "" {
+		spelling = fmt.Sprintf("%s (%s)", word.Kana, strings.Join(word.Kanji, ", "))
+	}
+	meaning := ""
+	if len(word.Gloss) > 0 {
+		meaning = strings.Join(word.Gloss[0].Meaning, ", ")
+	}
+
+	for answered := false; !answered; {
+		_, height := t.Size()
+		screen := []tui.Line{
+			{Text: formTitle(conj, positive, polite), Style: tui.Bold},
+			{},
+			{Text: spelling},
+			{Text: meaning, Style: tui.Dim},
+			{},
+			{Text: "Answer: " + input.String()},
+		}
+		for len(screen) < height-1 {
+			screen = append(screen, tui.Line{})
+		}
+		screen = append(screen, tui.Line{Text: "Enter answer  Esc quit", Style: tui.Dim})
+		t.Draw(screen, tui.Width("Answer: ")+input.Cursor(), 5)
+
+		ev, ok := <-t.Events()
+		if !ok {
+			interrupt(t)
+		}
+		switch ev.Key {
+		case tui.KeyEnter:
+			answered = strings.TrimSpace(input.String()) != ""
+		case tui.KeyEsc:
+			t.Close()
+			os.Exit(0)
+		case tui.KeyCtrlC:
+			interrupt(t)
+		default:
+			input.Handle(ev)
+		}
+	}
+
+	answer := strings.TrimSpace(input.String())
+	kana, _ := conj.Exec(word, positive, polite)
+	correct := isAnswer(answer, kana, conj.Kanji(word, positive, polite))
+
+	feedbackScreen(t, conjFeedback(word, conj, positive, polite, answer, correct), correct)
+	return correct
+}
+
+// choiceScreen lets the user choose one of the options for the form of
+// the item, with the arrow keys or the number of the option. Esc ends the
+// program.
+func choiceScreen(t *tui.Terminal, it *quizItem, choices []choice) choice {
+	spelling := it.word.Kana
+	if it.word.Kanji[0] != "" {
+		spelling = fmt.Sprintf("%s (%s)", it.word.Kana, strings.Join(it.word.Kanji, ", "))
+	}
+	meaning := ""
+	if len(it.word.Gloss) > 0 {
+		meaning = strings.Join(it.word.Gloss[0].Meaning, ", ")
+	}
+
+	selected := 0
+	for {
+		_, height := t.Size()
+		screen := []tui.Line{
+			{Text: formTitle(it.conj, it.positive, it.polite), Style: tui.Bold},
+			{},
+			{Text: spelling},
+			{Text: meaning, Style: tui.Dim},
+			{},
+		}
+		for i, c := range choices {
+			line := tui.Line{Text: fmt.Sprintf("  %d) %s", i+1, c.text)}
+			if i == selected {
+				line.Style = tui.Reverse
+			}
+			screen = append(screen, line)
+		}
+		for len(screen) < height-1 {
+			screen = append(screen, tui.Line{})
+		}
+		screen = append(screen, tui.Line{Text: fmt.Sprintf("↑/↓ select  1-%d/Enter choose  Esc quit", len(choices)), Style: tui.Dim})
+		t.Draw(screen, 0, -1)
+
+		ev, ok := <-t.Events()
+		if !ok {
+			interrupt(t)
+		}
+		switch ev.Key {
+		case tui.KeyUp:
+			selected = (selected + len(choices) - 1) % len(choices)
+		case tui.KeyDown, tui.KeyTab:
+			selected = (selected + 1) % len(choices)
+		case tui.KeyEnter:
+			return choices[selected]
+		case tui.KeyEsc:
+			t.Close()
+			os.Exit(0)
+		case tui.KeyCtrlC:
+			interrupt(t)
+		case tui.KeyRune:
+			if i := int(ev.Rune - '1'); i >= 0 && i < len(choices) {
+				return choices[i]
+			}
+		}
+	}
+}
+
+// feedbackScreen shows the feedback of conjFeedback until the user goes
+// on with Enter. Esc ends the program.
+func feedbackScreen(t *tui.Terminal, feedback string, correct bool) {
+	parts := strings.SplitN(feedback, "\n", 2)
+	style := tui.Green
+	if !correct {
+		style = tui.Red
+	}
+
+	ev := pager(t, tui.Line{Text: parts[0], Style: style}, func(width int) []tui.Line {
+		lines := textLines(parts[1], width)
+		if len(lines) > 0 && lines[0].Text == "" {
+			lines = lines[1:]
+		}
+		return lines
+	}, "Enter next  Esc quit", tui.KeyEnter, tui.KeyEsc)
+	if ev.Key == tui.KeyEsc {
+		t.Close()
+		os.Exit(0)
+	}
+}
+
+// tableScreen shows the conjugation table until it is closed with Enter,
+// Esc or q.
+func tableScreen(t *tui.Terminal, table *conjTable) {
+	var b strings.Builder
+	table.Write(&b, "text")
+
+	title := table.Kana
+	if len(table.Kanji) > 0 {
+		title = fmt.Sprintf("%s (%s)", table.Kana, strings.Join(table.Kanji, ", "))
+	}
+
+	pager(t, tui.Line{Text: title + "  " + table.Class, Style: tui.Bold}, func(width int) []tui.Line {
+		var lines []tui.Line
+		for _, l := range strings.Split(strings.TrimRight(b.String(), "\n"), "\n") {
+			lines = append(lines, tui.Line{Text: l})
+		}
+		return lines
+	}, "Esc close", tui.KeyEnter, tui.KeyEsc)
+}
@@ -0,0 +1,374 @@
+package main
+
+import (
+	"crypto/rand"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tsurai/msyu/conjugate"
+	"github.com/tsurai/msyu/dict"
+	"github.com/tsurai/msyu/romaji"
+)
+
+//go:embed web/index.html
+var quizPage []byte
+
+const (
+	// the most items a quiz session may have
+	maxQuizItems = 100
+	// quiz sessions are forgotten after this long without an answer
+	quizTimeout = time.Hour
+)
+
+var (
+	errNotFound  = errors.New("not found")
+	errNoSession = errors.New("unknown quiz session")
+)
+
+type quizSession struct {
+	items   []quizItem
+	current int
+	correct int
+	used    time.Time
+}
+
+type jsonQuestion struct {
+	Session    string   `json:"session"`
+	Number     int      `json:"number"`
+	Total      int      `json:"total"`
+	Word       jsonWord `json:"word"`
+	Form       string   `json:"form"`
+	Polarity   string   `json:"polarity"`
+	Politeness string   `json:"politeness"`
+}
+
+type jsonAnswer struct {
+	Correct    bool          `json:"correct"`
+	Kana       string        `json:"kana"`
+	Kanji      []string      `json:"kanji"`
+	Furigana   []string      `json:"furigana"`
+	Romaji     string        `json:"romaji"`
+	Derivation string        `json:"derivation"`
+	Score      int           `json:"score"`
+	Total      int           `json:"total"`
+	Next       *jsonQuestion `json:"next"`
+}
+
+type jsonDeinflected struct {
+	Chain []string `json:"chain"`
+	Word  jsonWord `json:"word"`
+}
+
+// quizServer keeps the running quiz sessions by id.
+type quizServer struct {
+	sync.Mutex
+	sessions map[string]*quizSession
+}
+
+// newServeMux returns the handler of the HTTP API and the quiz frontend.
+func newServeMux() *http.ServeMux {
+	qs := &quizServer{sessions: make(map[string]*quizSession)}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", servePage)
+	mux.HandleFunc("/api/search", serveSearch)
+	mux.HandleFunc("/api/conj", serveConj)
+	mux.HandleFunc("/api/deinflect", serveDeinflect)
+	mux.HandleFunc("/api/quiz", qs.serveNew)
+	mux.HandleFunc("/api/quiz/", qs.serveSession)
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, map[string]string{"error": err.Error()})
+}
+
+// allowMethod answers requests not using method with an error and reports
+// whether the request may be handled.
+func allowMethod(w http.ResponseWriter, r *http.Request, method string) bool {
+	if r.Method != method {
+		w.Header().Set("Allow", method)
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+		return false
+	}
+	return true
+}
+
+func servePage(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		writeError(w, http.StatusNotFound, errNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Write(quizPage)
+}
+
+// serveSearch answers GET /api/search?q=term with the matching words.
+// limit, offset and pos work like the flags of the search command.
+func serveSearch(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	q := r.URL.Query()
+	limit, _ := strconv.Atoi(q.Get("limit"))
+	offset, _ := strconv.Atoi(q.Get("offset"))
+	if q.Get("q") == "" || limit < 0 || offset < 0 {
+		writeError(w, http.StatusBadRequest, errors.New("missing or invalid parameter"))
+		return
+	}
+
+	words, err := lookup_words(q.Get("q"), dict.ALL)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if pos := q.Get("pos"); pos != "" {
+		var matches []*conjugate.Word
+		for _, word := range words {
+			if hasPos(word, strings.Split(pos, ",")) {
+				matches = append(matches, word)
+			}
+		}
+		words = matches
+	}
+
+	if offset < len(words) {
+		words = words[offset:]
+	} else {
+		words = nil
+	}
+	if limit > 0 && limit < len(words) {
+		words = words[:limit]
+	}
+
+	out := []jsonWord{}
+	for _, word := range words {
+		out = append(out, toJsonWord(word))
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// serveConj answers GET /api/conj?id=n&kana=reading with the conjugation
+// table of the word, as printed by conj -format json. explain=1 adds the
+// derivation of each form.
+func serveConj(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	q := r.URL.Query()
+	id, err := strconv.Atoi(q.Get("id"))
+	if err != nil || q.Get("kana") == "" {
+		writeError(w, http.StatusBadRequest, errors.New("missing or invalid parameter"))
+		return
+	}
+
+	word, err := dict.GetWord(id, q.Get("kana"))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	if word == nil || word.Conjugations() == nil {
+		writeError(w, http.StatusNotFound, errNotFound)
+		return
+	}
+
+	table, err := newConjTable(word, nil, q.Get("explain") == "1")
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, table)
+}
+
+// serveDeinflect answers GET /api/deinflect?text=form with the dictionary
+// words the form may belong to.
+func serveDeinflect(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodGet) {
+		return
+	}
+
+	text := r.URL.Query().Get("text")
+	if text == "" {
+		writeError(w, http.StatusBadRequest, errors.New("missing or invalid parameter"))
+		return
+	}
+	if isLatin(text) {
+		if kana, ok := romaji.ToKana(text); ok {
+			text = kana
+		}
+	}
+
+	results, err := dict.Deinflect(text)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	out := []jsonDeinflected{}
+	for _, res := range results {
+		chain := res.Chain
+		if chain == nil {
+			chain = []string{}
+		}
+		out = append(out, jsonDeinflected{chain, toJsonWord(res.Word)})
+	}
+	writeJSON(w, http.StatusOK, out)
+}
+
+// serveNew answers POST /api/quiz?n=items by starting a quiz session of
+// n random conjugations, 25 by default, and returns its first question.
+func (qs *quizServer) serveNew(w http.ResponseWriter, r *http.Request) {
+	if !allowMethod(w, r, http.MethodPost) {
+		return
+	}
+
+	n := 25
+	if s := r.URL.Query().Get("n"); s != "" {
+		var err error
+		if n, err = strconv.Atoi(s); err != nil || n <= 0 || n > maxQuizItems {
+			writeError(w, http.StatusBadRequest, errors.New("missing or invalid parameter"))
+			return
+		}
+	}
+
+	words, err := dict.RandomWords(n, dict.CONJUGABLE)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	session := &quizSession{used: time.Now()}
+	for _, word := range words {
+		it, err := (&quizOptions{}).pick(word)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if it != nil {
+			session.items = append(session.items, *it)
+		}
+	}
+	if len(session.items) == 0 {
+		writeError(w, http.StatusInternalServerError, errors.New("no words found"))
+		return
+	}
+
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		writeError(w, http.StatusInternalServerError, err)
+		return
+	}
+	id := hex.EncodeToString(b)
+
+	qs.Lock()
+	for k, s := range qs.sessions {
+		if time.Since(s.used) > quizTimeout {
+			delete(qs.sessions, k)
+		}
+	}
+	qs.sessions[id] = session
+	qs.Unlock()
+
+	writeJSON(w, http.StatusOK, session.question(id))
+}
+
+// serveSession answers GET /api/quiz/id with the current question of the
+// session and POST /api/quiz/id with {"answer": "..."} by checking the
+// answer to it. The session ends after its last answer.
+func (qs *quizServer) serveSession(w http.ResponseWriter, r *http.Request) {
+	id := strings.TrimPrefix(r.URL.Path, "/api/quiz/")
+
+	qs.Lock()
+	defer qs.Unlock()
+
+	session := qs.sessions[id]
+	if session == nil {
+		writeError(w, http.StatusNotFound, errNoSession)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, session.question(id))
+
+	case http.MethodPost:
+		var req struct {
+			Answer string `json:"answer"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+
+		res := session.answer(strings.TrimSpace(req.Answer))
+		if res.Next = session.question(id); res.Next == nil {
+			delete(qs.sessions, id)
+		}
+		writeJSON(w, http.StatusOK, res)
+
+	default:
+		w.Header().Set("Allow", "GET, POST")
+		writeError(w, http.StatusMethodNotAllowed, errors.New("method not allowed"))
+	}
+}
+
+// question returns the current question of the session, nil if every
+// question has been answered.
+func (s *quizSession) question(id string) *jsonQuestion {
+	if s.current >= len(s.items) {
+		return nil
+	}
+
+	it := s.items[s.current]
+	q := &jsonQuestion{Session: id, Number: s.current + 1, Total: len(s.items), Word: toJsonWord(it.word),
+		Form: it.conj.Name, Polarity: "Positive", Politeness: "Plain"}
+	if !it.positive {
+		q.Polarity = "Negative"
+	}
+	if it.polite {
+		q.Politeness = "Polite"
+	}
+	return q
+}
+
+// answer checks input against the current question and moves on to the
+// next one.
+func (s *quizSession) answer(input string) *jsonAnswer {
+	it := s.items[s.current]
+	kana, _ := it.conj.Exec(it.word, it.positive, it.polite)
+	kanji := it.conj.Kanji(it.word, it.positive, it.polite)
+
+	res := &jsonAnswer{Kana: kana, Kanji: []string{}, Furigana: []string{},
+		Romaji:     romaji.FromKana(kana, romaji.Hepburn),
+		Derivation: explainForm(it.word, it.conj.Form, it.positive, it.polite)}
+	for _, k := range kanji {
+		res.Kanji = append(res.Kanji, k)
+		res.Furigana = append(res.Furigana, furigana(k, kana))
+	}
+
+	res.Correct = isAnswer(input, kana, kanji)
+	if res.Correct {
+		s.correct++
+	}
+	s.current++
+	s.used = time.Now()
+
+	res.Score, res.Total = s.correct, s.current
+	return res
+}
@@ -0,0 +1,177 @@
+package main
+
+import (
+	"database/sql"
+	"log"
+	"math"
+	"time"
+
+	"github.com/tsurai/msyu/conjugate"
+)
+
+const (
+	// grades given to an answer, on the 0-5 scale of SM-2
+	gradeWrong   = 1
+	gradeCorrect = 4
+
+	// answers graded below this restart the item's repetitions
+	srsPassingGrade = 3
+	srsInitialEase  = 2.5
+	srsMinimumEase  = 1.3
+)
+
+var srsSchema = []string{
+	"CREATE TABLE IF NOT EXISTS item (id INTEGER PRIMARY KEY, word INTEGER NOT NULL, " +
+		"kana TEXT NOT NULL, conj TEXT NOT NULL, positive INTEGER NOT NULL, polite INTEGER NOT NULL, " +
+		"repetitions INTEGER NOT NULL, interval INTEGER NOT NULL, ease REAL NOT NULL, due INTEGER NOT NULL, " +
+		"UNIQUE (word, kana, conj, positive, polite))",
+	"CREATE TABLE IF NOT EXISTS review (id INTEGER PRIMARY KEY, item INTEGER NOT NULL, " +
+		"time INTEGER NOT NULL, grade INTEGER NOT NULL)",
+	// the JMdict words of the vocabulary unlocked on WaniKani
+	"CREATE TABLE IF NOT EXISTS wanikani (subject INTEGER NOT NULL, word INTEGER NOT NULL, kana TEXT NOT NULL, " +
+		"UNIQUE (subject, word, kana))",
+	"CREATE INDEX IF NOT EXISTS item_due ON item (due)",
+	"CREATE INDEX IF NOT EXISTS review_item ON review (item)",
+	// the potential became a form of its own
+	"UPDATE OR IGNORE item SET conj = 'Passive' WHERE conj = 'Passive & Potentional'",
+}
+
+// srsItem is a single reviewable (word, conjugation, polarity, politeness)
+// combination together with its SM-2 scheduling state.
+type srsItem struct {
+	id          int64
+	word        int
+	kana        string
+	conj        string
+	positive    bool
+	polite      bool
+	repetitions int
+	interval    int // days
+	ease        float64
+	due         time.Time
+}
+
+var progress *sql.DB = nil
+
+func SRS_init() {
+	var err error
+	progress, err = sql.Open("sqlite3", "progress.db")
+	if err != nil {
+		log.Fatal("A database error has occured:", err)
+	}
+
+	for _, stmt := range srsSchema {
+		if _, err := progress.Exec(stmt); err != nil {
+			log.Fatal("A database error has occured:", err)
+		}
+	}
+}
+
+func SRS_close() {
+	progress.Close()
+}
+
+// schedule applies the SM-2 algorithm to the item for an answer of the
+// given grade at time now.
+func (it *srsItem) schedule(grade int, now time.Time) {
+	if grade >= srsPassingGrade {
+		switch it.repetitions {
+		case 0:
+			it.interval = 1
+		case 1:
+			it.interval = 6
+		default:
+			it.interval = int(math.Round(float64(it.interval) * it.ease))
+		}
+		it.repetitions++
+	} else {
+		it.repetitions = 0
+		it.interval = 1
+	}
+
+	q := float64(5 - grade)
+	it.ease += 0.1 - q*(0.08+q*0.02)
+	if it.ease < srsMinimumEase {
+		it.ease = srsMinimumEase
+	}
+
+	it.due = now.AddDate(0, 0, it.interval)
+}
+
+// SRS_record stores a review of the given item and reschedules it.
+func SRS_record(w *conjugate.Word, conj string, positive bool, polite bool, grade int) {
+	now := time.Now()
+	it := &srsItem{word: w.ID, kana: w.Kana, conj: conj, positive: positive, polite: polite, ease: srsInitialEase}
+
+	row := progress.QueryRow("SELECT id, repetitions, interval, ease FROM item "+
+		"WHERE word = ? AND kana = ? AND conj = ? AND positive = ? AND polite = ?",
+		it.word, it.kana, it.conj, it.positive, it.polite)
+	err := row.Scan(&it.id, &it.repetitions, &it.interval, &it.ease)
+	if err != nil && err != sql.ErrNoRows {
+		log.Fatal("A database error has occured:", err)
+	}
+
+	it.schedule(grade, now)
+
+	tx, err := progress.Begin()
+	if err != nil {
+		log.Fatal("A database error has occured:", err)
+	}
+
+	if it.id == 0 {
+		res, err := tx.Exec("INSERT INTO item (word, kana, conj, positive, polite, repetitions, interval, ease, due) "+
+			"VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)",
+			it.word, it.kana, it.conj, it.positive, it.polite, it.repetitions, it.interval, it.ease, it.due.Unix())
+		if err == nil {
+			it.id, err = res.LastInsertId()
+		}
+		if err != nil {
+			tx.Rollback()
+			log.Fatal("A database error has occured:", err)
+		}
+	} else {
+		_, err := tx.Exec("UPDATE item SET repetitions = ?, interval = ?, ease = ?, due = ? WHERE id = ?",
+			it.repetitions, it.interval, it.ease, it.due.Unix(), it.id)
+		if err != nil {
+			tx.Rollback()
+			log.Fatal("A database error has occured:", err)
+		}
+	}
+
+	if _, err := tx.Exec("INSERT INTO review (item, time, grade) VALUES (?, ?, ?)", it.id, now.Unix(), grade); err != nil {
+		tx.Rollback()
+		log.Fatal("A database error has occured:", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Fatal("A database error has occured:", err)
+	}
+}
+
+// SRS_get_due_items returns up to n items whose review is due, the most
+// overdue first.
+func SRS_get_due_items(n int) []*srsItem {
+	rows, err := progress.Query("SELECT id, word, kana, conj, positive, polite, repetitions, interval, ease, due "+
+		"FROM item WHERE due <= ? ORDER BY due LIMIT ?", time.Now().Unix(), n)
+	if err != nil {
+		log.Fatal("A database error has occured:", err)
+	}
+	defer rows.Close()
+
+	var items []*srsItem
+	for rows.Next() {
+		var due int64
+		it := &srsItem{}
+
+		err := rows.Scan(&it.id, &it.word, &it.kana, &it.conj, &it.positive, &it.polite,
+			&it.repetitions, &it.interval, &it.ease, &due)
+		if err != nil {
+			log.Fatal("A database error has occured:", err)
+		}
+
+		it.due = time.Unix(due, 0)
+		items = append(items, it)
+	}
+
+	return items
+}
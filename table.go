@@ -0,0 +1,246 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+
+	"github.com/tsurai/msyu/conjugate"
+	"github.com/tsurai/msyu/dict"
+	"github.com/tsurai/msyu/tui"
+)
+
+var formats = []string{"text", "markdown", "csv", "json", "html"}
+
+var errUnknownFormat = errors.New("unknown table format")
+
+// conjRow is a single form of the conjugation table.
+type conjRow struct {
+	Form     string   `json:"form"`
+	Positive bool     `json:"positive"`
+	Polite   bool     `json:"polite"`
+	Kanji    []string `json:"kanji,omitempty"`
+	Furigana []string `json:"furigana,omitempty"`
+	Kana     string   `json:"kana"`
+	Romaji   string   `json:"romaji,omitempty"`
+	Trace    string   `json:"trace,omitempty"`
+}
+
+type conjTable struct {
+	Kana    string    `json:"kana"`
+	Kanji   []string  `json:"kanji"`
+	Class   string    `json:"class"`
+	Accents []int     `json:"accents,omitempty"`
+	Forms   []conjRow `json:"forms"`
+}
+
+var htmlTable = template.Must(template.New("table").Parse(`<table>
+{{- if .Pitch}}
+  <caption>{{.Pitch}}</caption>
+{{- end}}
+  <thead>
+    <tr>{{range .Header}}<th>{{.}}</th>{{end}}</tr>
+  </thead>
+  <tbody>
+{{- range .Rows}}
+    <tr>{{range .}}<td>{{.}}</td>{{end}}</tr>
+{{- end}}
+  </tbody>
+</table>
+`))
+
+// newConjTable builds the table of every conjugation of w in the order of
+// w.Conjugations(), leaving out the combinations a form doesn't have.
+// Every kanji spelling of w is conjugated. If
+// roma is not nil it is used to add the romanization of each form. If
+// explain is set the derivation of each form is added. The pitch accents
+// of the word are added if they have been imported.
+func newConjTable(w *conjugate.Word, roma func(string) string, explain bool) (*conjTable, error) {
+	accents, err := dict.Accents(w)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &conjTable{Kana: w.Kana, Kanji: []string{}, Class: w.Class(), Accents: accents, Forms: []conjRow{}}
+
+	for _, k := range w.Kanji {
+		if k != "" {
+			t.Kanji = append(t.Kanji, k)
+		}
+	}
+
+	for _, c := range w.Conjugations() {
+		for _, positive := range []bool{true, false} {
+			for _, polite := range []bool{false, true} {
+				kana, _ := c.Exec(w, positive, polite)
+				if kana == "" {
+					continue
+				}
+				kanji := c.Kanji(w, positive, polite)
+				row := conjRow{Form: c.Name, Positive: positive, Polite: polite, Kanji: kanji, Kana: kana}
+				for _, k := range kanji {
+					row.Furigana = append(row.Furigana, furigana(k, kana))
+				}
+				if roma != nil {
+					row.Romaji = roma(kana)
+				}
+				if explain {
+					row.Trace = explainForm(w, c.Form, positive, polite)
+				}
+				t.Forms = append(t.Forms, row)
+			}
+		}
+	}
+
+	return t, nil
+}
+
+// cells returns the header and rows of the table as strings. The kanji,
+// romaji and derivation columns are left out if they'd be empty.
+func (t *conjTable) cells() ([]string, [][]string) {
+	hasKanji := len(t.Kanji) > 0
+	hasRomaji := len(t.Forms) > 0 && t.Forms[0].Romaji != ""
+	hasTrace := len(t.Forms) > 0 && t.Forms[0].Trace != ""
+
+	header := []string{"Form", "Polarity", "Politeness"}
+	if hasKanji {
+		header = append(header, "Kanji")
+	}
+	header = append(header, "Kana")
+	if hasRomaji {
+		header = append(header, "Romaji")
+	}
+	if hasTrace {
+		header = append(header, "Derivation")
+	}
+
+	var rows [][]string
+	for _, f := range t.Forms {
+		polarity, politeness := "Positive", "Plain"
+		if !f.Positive {
+			polarity = "Negative"
+		}
+		if f.Polite {
+			politeness = "Polite"
+		}
+
+		row := []string{f.Form, polarity, politeness}
+		if hasKanji {
+			row = append(row, strings.Join(f.Kanji, ", "))
+		}
+		row = append(row, f.Kana)
+		if hasRomaji {
+			row = append(row, f.Romaji)
+		}
+		if hasTrace {
+			row = append(row, f.Trace)
+		}
+		rows = append(rows, row)
+	}
+
+	return header, rows
+}
+
+// Write renders the table to out in the given format. The pitch accents
+// are written above the text and markdown tables and as caption of the
+// html one.
+func (t *conjTable) Write(out io.Writer, format string) error {
+	header, rows := t.cells()
+
+	var pitch string
+	if len(t.Accents) > 0 {
+		pitch = "Pitch: " + formatAccents(t.Kana, t.Accents)
+	}
+
+	switch format {
+	case "text":
+		if pitch != "" {
+			fmt.Fprintf(out, "%s\n\n", pitch)
+		}
+		writeTextTable(out, header, rows)
+	case "markdown":
+		if pitch != "" {
+			fmt.Fprintf(out, "%s\n\n", pitch)
+		}
+		writeMarkdownTable(out, header, rows)
+	case "csv":
+		cw := csv.NewWriter(out)
+		cw.Write(header)
+		cw.WriteAll(rows)
+		return cw.Error()
+	case "json":
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(t)
+	case "html":
+		return htmlTable.Execute(out, struct {
+			Pitch  string
+			Header []string
+			Rows   [][]string
+		}{pitch, header, rows})
+	default:
+		return errUnknownFormat
+	}
+
+	return nil
+}
+
+func pad(s string, width int) string {
+	return s + strings.Repeat(" ", width-tui.Width(s))
+}
+
+func columnWidths(header []string, rows [][]string) []int {
+	widths := make([]int, len(header))
+	for _, row := range append([][]string{header}, rows...) {
+		for i, c := range row {
+			if w := tui.Width(c); w > widths[i] {
+				widths[i] = w
+			}
+		}
+	}
+	return widths
+}
+
+func writeTextTable(out io.Writer, header []string, rows [][]string) {
+	widths := columnWidths(header, rows)
+
+	line := func(row []string) {
+		cells := make([]string, len(row))
+		for i, c := range row {
+			cells[i] = pad(c, widths[i])
+		}
+		fmt.Fprintln(out, strings.TrimRight(strings.Join(cells, "  "), " "))
+	}
+
+	line(header)
+	for i, row := range rows {
+		// separate the forms from each other
+		if i > 0 && row[0] != rows[i-1][0] {
+			fmt.Fprintln(out)
+		}
+		line(row)
+	}
+}
+
+func writeMarkdownTable(out io.Writer, header []string, rows [][]string) {
+	escape := strings.NewReplacer("|", `\|`)
+
+	fmt.Fprintf(out, "| %s |\n", strings.Join(header, " | "))
+	sep := make([]string, len(header))
+	for i := range sep {
+		sep[i] = "---"
+	}
+	fmt.Fprintf(out, "| %s |\n", strings.Join(sep, " | "))
+
+	for _, row := range rows {
+		cells := make([]string, len(row))
+		for i, c := range row {
+			cells[i] = escape.Replace(c)
+		}
+		fmt.Fprintf(out, "| %s |\n", strings.Join(cells, " | "))
+	}
+}
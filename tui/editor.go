@@ -0,0 +1,56 @@
+package tui
+
+// Editor is a single line text input.
+type Editor struct {
+	text []rune
+	pos  int
+}
+
+// String returns the text entered.
+func (e *Editor) String() string {
+	return string(e.text)
+}
+
+// Reset empties the editor.
+func (e *Editor) Reset() {
+	e.text, e.pos = nil, 0
+}
+
+// Cursor returns the column of the cursor.
+func (e *Editor) Cursor() int {
+	return Width(string(e.text[:e.pos]))
+}
+
+// Handle edits the text according to the key press and reports whether
+// the key was used.
+func (e *Editor) Handle(ev Event) bool {
+	switch ev.Key {
+	case KeyRune:
+		e.text = append(e.text[:e.pos], append([]rune{ev.Rune}, e.text[e.pos:]...)...)
+		e.pos++
+	case KeyBackspace:
+		if e.pos > 0 {
+			e.text = append(e.text[:e.pos-1], e.text[e.pos:]...)
+			e.pos--
+		}
+	case KeyDelete:
+		if e.pos < len(e.text) {
+			e.text = append(e.text[:e.pos], e.text[e.pos+1:]...)
+		}
+	case KeyLeft:
+		if e.pos > 0 {
+			e.pos--
+		}
+	case KeyRight:
+		if e.pos < len(e.text) {
+			e.pos++
+		}
+	case KeyHome:
+		e.pos = 0
+	case KeyEnd:
+		e.pos = len(e.text)
+	default:
+		return false
+	}
+	return true
+}
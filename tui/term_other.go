@@ -0,0 +1,23 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+
+package tui
+
+import (
+	"os"
+)
+
+type termState struct{}
+
+func makeRaw(f *os.File) (*termState, error) {
+	return nil, ErrNoTerminal
+}
+
+func restore(f *os.File, s *termState) error {
+	return nil
+}
+
+func size(f *os.File) (int, int, error) {
+	return 0, 0, ErrNoTerminal
+}
+
+func notifyResize(c chan<- os.Signal) {}
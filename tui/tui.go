@@ -0,0 +1,342 @@
+// Package tui draws full-screen terminal interfaces. It switches the
+// terminal to raw mode, reads single key presses and redraws the screen
+// in place with ANSI escape sequences instead of clearing it.
+package tui
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Key identifies a key press. Printable characters are KeyRune.
+type Key int
+
+const (
+	KeyRune Key = iota
+	KeyEnter
+	KeyBackspace
+	KeyDelete
+	KeyTab
+	KeyEsc
+	KeyUp
+	KeyDown
+	KeyLeft
+	KeyRight
+	KeyHome
+	KeyEnd
+	KeyPageUp
+	KeyPageDown
+	KeyCtrlC
+	KeyCtrlD
+	// KeyResize is sent when the terminal window has been resized.
+	KeyResize
+)
+
+// Event is a key press, Rune holding the character of KeyRune.
+type Event struct {
+	Key  Key
+	Rune rune
+}
+
+// Style is the look of a line.
+type Style int
+
+const (
+	Normal Style = iota
+	Bold
+	Dim
+	Reverse
+	Green
+	Red
+)
+
+var styleCodes = map[Style]string{Bold: "1", Dim: "2", Reverse: "7", Green: "32", Red: "31"}
+
+// Line is a line of the screen.
+type Line struct {
+	Text  string
+	Style Style
+}
+
+var ErrNoTerminal = errors.New("tui: not a terminal")
+
+// Terminal is the screen while it is in full-screen mode.
+type Terminal struct {
+	in    *os.File
+	out   *bufio.Writer
+	state *termState
+}
+
+var (
+	// the terminal currently open, restored by Restore
+	current     *Terminal
+	currentLock sync.Mutex
+
+	// stdin is read by a single goroutine for the lifetime of the
+	// program, as a read can't be interrupted once a terminal is closed
+	events     = make(chan Event, 16)
+	readerOnce sync.Once
+)
+
+// Open switches the terminal to raw mode and the alternate screen, which
+// keeps the content of the terminal from before. It returns
+// ErrNoTerminal if stdin isn't a terminal.
+func Open() (*Terminal, error) {
+	state, err := makeRaw(os.Stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Terminal{in: os.Stdin, out: bufio.NewWriter(os.Stdout), state: state}
+	readerOnce.Do(func() {
+		go readEvents(os.Stdin)
+		go watchResize()
+	})
+
+	currentLock.Lock()
+	current = t
+	currentLock.Unlock()
+
+	t.out.WriteString("\x1b[?1049h\x1b[?25l")
+	t.out.Flush()
+	return t, nil
+}
+
+// Close leaves the alternate screen and restores the terminal mode from
+// before Open.
+func (t *Terminal) Close() error {
+	currentLock.Lock()
+	defer currentLock.Unlock()
+
+	if t.state == nil {
+		return nil
+	}
+	if current == t {
+		current = nil
+	}
+
+	t.out.WriteString("\x1b[0m\x1b[?25h\x1b[?1049l")
+	t.out.Flush()
+	err := restore(t.in, t.state)
+	t.state = nil
+	return err
+}
+
+// Restore closes the open terminal, if any. It is meant to be called
+// before exiting the program, e.g. on fatal errors.
+func Restore() {
+	currentLock.Lock()
+	t := current
+	currentLock.Unlock()
+
+	if t != nil {
+		t.Close()
+	}
+}
+
+// Size returns the width and height of the terminal. 80x24 is assumed if
+// it can't be determined.
+func (t *Terminal) Size() (int, int) {
+	w, h, err := size(os.Stdout)
+	if err != nil || w <= 0 || h <= 0 {
+		return 80, 24
+	}
+	return w, h
+}
+
+// Events returns the channel key presses and resizes are sent on.
+func (t *Terminal) Events() <-chan Event {
+	return events
+}
+
+// Draw replaces the screen content with lines, cutting off the lines that
+// don't fit. If cursorY is not negative the cursor is shown at the given
+// column and line.
+func (t *Terminal) Draw(lines []Line, cursorX int, cursorY int) {
+	width, height := t.Size()
+
+	t.out.WriteString("\x1b[?25l\x1b[H")
+	for i := 0; i < height && i < len(lines); i++ {
+		if i > 0 {
+			t.out.WriteString("\r\n")
+		}
+
+		text := Truncate(lines[i].Text, width)
+		if code, ok := styleCodes[lines[i].Style]; ok {
+			fmt.Fprintf(t.out, "\x1b[%sm%s\x1b[0m", code, text)
+		} else {
+			t.out.WriteString(text)
+		}
+		t.out.WriteString("\x1b[K")
+	}
+	// clear everything below the last line
+	t.out.WriteString("\x1b[J")
+
+	if cursorY >= 0 {
+		fmt.Fprintf(t.out, "\x1b[%d;%dH\x1b[?25h", cursorY+1, cursorX+1)
+	}
+	t.out.Flush()
+}
+
+// Width returns the number of terminal columns s takes up, counting
+// japanese and other east asian wide characters twice.
+func Width(s string) int {
+	n := 0
+	for _, r := range s {
+		n += runeWidth(r)
+	}
+	return n
+}
+
+func runeWidth(r rune) int {
+	if unicode.Is(unicode.Han, r) || unicode.Is(unicode.Hiragana, r) ||
+		unicode.Is(unicode.Katakana, r) || (r >= '　' && r <= '〿') ||
+		(r >= '！' && r <= '｠') || r == 'ー' {
+		return 2
+	}
+	return 1
+}
+
+// Truncate cuts s off after width columns.
+func Truncate(s string, width int) string {
+	n := 0
+	for i, r := range s {
+		if n += runeWidth(r); n > width {
+			return s[:i]
+		}
+	}
+	return s
+}
+
+// Skip cuts off the first width columns of s.
+func Skip(s string, width int) string {
+	n := 0
+	for i, r := range s {
+		if n >= width {
+			return s[i:]
+		}
+		n += runeWidth(r)
+	}
+	return ""
+}
+
+// Wrap breaks s into lines of at most width columns, breaking at spaces
+// where possible. Lines that fit are kept as they are.
+func Wrap(s string, width int) []string {
+	var lines []string
+	for _, para := range strings.Split(s, "\n") {
+		if Width(para) <= width {
+			lines = append(lines, para)
+			continue
+		}
+
+		line := ""
+		for _, word := range strings.Split(para, " ") {
+			switch {
+			case line == "":
+				line = word
+			case Width(line)+1+Width(word) <= width:
+				line += " " + word
+			default:
+				lines = append(lines, line)
+				line = word
+			}
+
+			for Width(line) > width && width > 0 {
+				head := Truncate(line, width)
+				if head == "" {
+					break
+				}
+				lines = append(lines, head)
+				line = line[len(head):]
+			}
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func watchResize() {
+	c := make(chan os.Signal, 1)
+	notifyResize(c)
+	for range c {
+		events <- Event{Key: KeyResize}
+	}
+}
+
+func readEvents(in *os.File) {
+	buf := make([]byte, 256)
+	for {
+		n, err := in.Read(buf)
+		if err != nil {
+			close(events)
+			return
+		}
+		for _, e := range parseEvents(buf[:n]) {
+			events <- e
+		}
+	}
+}
+
+var escapes = map[string]Key{
+	"[A": KeyUp, "[B": KeyDown, "[C": KeyRight, "[D": KeyLeft,
+	"OA": KeyUp, "OB": KeyDown, "OC": KeyRight, "OD": KeyLeft,
+	"[H": KeyHome, "[F": KeyEnd, "OH": KeyHome, "OF": KeyEnd,
+	"[1~": KeyHome, "[7~": KeyHome, "[4~": KeyEnd, "[8~": KeyEnd,
+	"[3~": KeyDelete, "[5~": KeyPageUp, "[6~": KeyPageDown,
+}
+
+// parseEvents splits the bytes of a single read into key presses. An
+// escape character on its own is the escape key, else the start of the
+// sequence sent for a special key.
+func parseEvents(b []byte) []Event {
+	var evs []Event
+	for len(b) > 0 {
+		switch b[0] {
+		case '\r', '\n':
+			evs = append(evs, Event{Key: KeyEnter})
+		case 0x7f, 0x08:
+			evs = append(evs, Event{Key: KeyBackspace})
+		case '\t':
+			evs = append(evs, Event{Key: KeyTab})
+		case 0x03:
+			evs = append(evs, Event{Key: KeyCtrlC})
+		case 0x04:
+			evs = append(evs, Event{Key: KeyCtrlD})
+		case 0x1b:
+			// the sequence ends with the first letter or ~
+			end := 1
+			if len(b) > 1 && (b[1] == '[' || b[1] == 'O') {
+				for end = 2; end < len(b) && end < 8; end++ {
+					if c := b[end]; c == '~' || (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') {
+						end++
+						break
+					}
+				}
+			}
+
+			if key, ok := escapes[string(b[1:end])]; ok {
+				evs = append(evs, Event{Key: key})
+			} else if end == 1 {
+				evs = append(evs, Event{Key: KeyEsc})
+			}
+			b = b[end:]
+			continue
+		default:
+			r, n := utf8.DecodeRune(b)
+			if r != utf8.RuneError && r >= ' ' {
+				evs = append(evs, Event{Key: KeyRune, Rune: r})
+			}
+			b = b[n:]
+			continue
+		}
+		b = b[1:]
+	}
+	return evs
+}
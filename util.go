@@ -1,26 +1,256 @@
 package main
 
 import (
+	"bufio"
 	"fmt"
+	"io"
+	"log"
 	"os"
-	"os/exec"
+	"strconv"
 	"strings"
 	"unicode"
+
+	"github.com/tsurai/msyu/conjugate"
+	"github.com/tsurai/msyu/dict"
+	"github.com/tsurai/msyu/romaji"
 )
 
-func (w *word) Print() {
-	if w.kanji[0] != "" {
-		fmt.Printf("%s (%s)\n", w.kana, strings.Join(w.kanji, ", "))
+// smallKana are written together with the preceding kana as one mora
+const smallKana = "ゃゅょぁぃぅぇぉゎャュョァィゥェォヮ"
+
+func printWord(w *conjugate.Word) {
+	for _, l := range wordLines(w) {
+		fmt.Println(l)
+	}
+}
+
+// wordLines returns the lines printWord prints for w: its spellings and
+// pitch accents followed by the parts of speech and meanings of each
+// sense.
+func wordLines(w *conjugate.Word) []string {
+	accents, err := dict.Accents(w)
+	if err != nil {
+		log.Fatal("A database error has occured:", err)
+	}
+
+	var head string
+	if w.Kanji[0] != "" {
+		head = fmt.Sprintf("%s (%s)", w.Kana, strings.Join(w.Kanji, ", "))
 	} else {
-		fmt.Printf("%s\n", w.kana)
+		head = w.Kana
+	}
+	if len(accents) > 0 {
+		head += "  " + formatAccents(w.Kana, accents)
+	}
+
+	lines := []string{head}
+	for _, g := range w.Gloss {
+		if g.Pos[0] != "" {
+			lines = append(lines, fmt.Sprintf("    t: %s", strings.Join(g.Pos, ", ")))
+		}
+		lines = append(lines, fmt.Sprintf("        * %s ", strings.Join(g.Meaning, ", ")))
+	}
+	return lines
+}
+
+// search_word looks up w in the dictionary and lets the user choose an
+// entry if more than one matches. It returns nil if nothing was found.
+func search_word(w string, filter int) *conjugate.Word {
+	words, err := lookup_words(w, filter)
+	if err != nil {
+		log.Fatal("A database error has occured:", err)
+	}
+
+	if len(words) == 0 {
+		return nil
+	}
+	return select_word(words)
+}
+
+// lookup_words searches the dictionary for w. Japanese input is searched
+// for in the readings and kanji spellings, any other input in the english
+// glosses. Latin input is searched for in the readings as well, read as
+// romaji, as many english words are valid romaji as well, e.g. come or
+// take. Words read exactly like the romaji come first, followed by the
+// words with a meaning of exactly w, the other english matches and the
+// other readings containing the romaji.
+func lookup_words(w string, filter int) ([]*conjugate.Word, error) {
+	if isJapaneseString(w) {
+		return dict.Search(w, dict.JAP, filter)
+	}
+
+	english, err := dict.Search(w, dict.EN, filter)
+	if err != nil || !isLatin(w) {
+		return english, err
+	}
+	kana, ok := romaji.ToKana(w)
+	if !ok {
+		return english, nil
+	}
+	readings, err := dict.Search(kana, dict.JAP, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var tiers [4][]*conjugate.Word
+	for _, r := range readings {
+		if r.Kana == kana {
+			tiers[0] = append(tiers[0], r)
+		} else {
+			tiers[3] = append(tiers[3], r)
+		}
+	}
+	for _, e := range english {
+		if hasMeaning(e, w) {
+			tiers[1] = append(tiers[1], e)
+		} else {
+			tiers[2] = append(tiers[2], e)
+		}
+	}
+
+	var words []*conjugate.Word
+	seen := make(map[string]bool)
+	for _, tier := range tiers {
+		for _, word := range tier {
+			key := strconv.Itoa(word.ID) + word.Kana
+			if !seen[key] {
+				seen[key] = true
+				words = append(words, word)
+			}
+		}
+	}
+	return words, nil
+}
+
+// hasMeaning reports whether a meaning of w is exactly s, ignoring case
+// and the "to" of verbs.
+func hasMeaning(w *conjugate.Word, s string) bool {
+	for _, g := range w.Gloss {
+		for _, m := range g.Meaning {
+			m = strings.TrimSpace(m)
+			if strings.EqualFold(m, s) || strings.EqualFold(m, "to "+s) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// select_word prints the words page by page and asks the user to choose
+// one of them.
+func select_word(words []*conjugate.Word) *conjugate.Word {
+	step_size := 5
+	num := len(words)
+
+	if num > 1 {
+		if t := openScreen(); t != nil {
+			defer t.Close()
+			return selectScreen(t, words)
+		}
+	}
+
+	if num > 1 {
+		offset := 0
+
+		for {
+			fmt.Println("--------------------")
+			for i := offset; i < offset+step_size && i < num; i++ {
+				w := words[i]
+
+				fmt.Printf("%d: ", i+1)
+				printWord(w)
+			}
+
+			valid := false
+			for !valid {
+				info := "%d-%d of %d"
+
+				if offset+5 < num {
+					info += " | <n> for next"
+				}
+				if offset >= 5 {
+					info += " | <p> for previous"
+				}
+				fmt.Printf(info+"\nSelect an Entry : ", offset+1, offset+step_size, num)
+				entry, err := readLine()
+				if err != nil {
+					fmt.Println()
+					return nil
+				}
+
+				if entry == "n" {
+					if offset+step_size < num {
+						offset = offset + step_size
+						valid = true
+					}
+				} else if entry == "p" {
+					if (offset - step_size) >= 0 {
+						offset = offset - step_size
+						valid = true
+					}
+				} else if i, err := strconv.Atoi(entry); err == nil && i > 0 && i <= num {
+					return words[i-1]
+				}
+
+				if !valid {
+					fmt.Println("Invalid input. Try again\n--------------------")
+				}
+			}
+		}
+	}
+	return words[0]
+}
+
+// furigana returns the kanji spelling with the reading of each kanji
+// run in brackets, 食[た]べる, or the spelling as is if it doesn't fit the
+// reading.
+func furigana(kanji string, kana string) string {
+	if segments := conjugate.Align(kanji, kana); segments != nil {
+		return conjugate.Furigana(segments)
+	}
+	return kanji
+}
+
+// formatAccents returns the pitch patterns of kana with the given
+// downsteps, e.g. "かꜜく [1]" for 書く.
+func formatAccents(kana string, accents []int) string {
+	var patterns []string
+	for _, n := range accents {
+		patterns = append(patterns, fmt.Sprintf("%s [%d]", pitchMarks(kana, n), n))
+	}
+	return strings.Join(patterns, ", ")
+}
+
+// pitchMarks returns kana with ꜛ after the mora the pitch rises after and
+// ꜜ after the mora it drops after: はꜛしꜜ for downstep 2. The pitch rises
+// after the first mora unless it drops there.
+func pitchMarks(kana string, downstep int) string {
+	m := morae(kana)
+	out := ""
+
+	for i, mora := range m {
+		out += mora
+		if i+1 == downstep {
+			out += "ꜜ"
+		} else if i == 0 && downstep != 1 && len(m) > 1 {
+			out += "ꜛ"
+		}
 	}
+	return out
+}
 
-	for _, g := range w.gloss {
-		if g.pos[0] != "" {
-			fmt.Printf("    t: %s\n", strings.Join(g.pos, ", "))
+// morae splits kana into its morae. Small kana like the ょ of きょ belong
+// to the preceding mora, っ, ん and ー are morae of their own.
+func morae(kana string) []string {
+	var m []string
+	for _, r := range kana {
+		if len(m) > 0 && strings.ContainsRune(smallKana, r) {
+			m[len(m)-1] += string(r)
+		} else {
+			m = append(m, string(r))
 		}
-		fmt.Printf("        * %s \n", strings.Join(g.meaning, ", "))
 	}
+	return m
 }
 
 func isLatin(s string) bool {
@@ -47,11 +277,58 @@ func isJapaneseString(s string) bool {
 	return true
 }
 
+// clear clears the terminal and moves the cursor to the top left corner.
 func clear() {
-	cmd := exec.Command("clear")
-	cmd.Stdout = os.Stdout
-	cmd.Run()
+	fmt.Print("\x1b[H\x1b[2J")
+}
 
-	cmd = exec.Command("cmd", "/c", "cls & clear")
-	cmd.Run()
+var stdin = bufio.NewReader(os.Stdin)
+
+// readLine reads a line from stdin and returns it without the line
+// break and surrounding spaces. io.EOF is returned once stdin has been
+// closed and there is nothing left to read.
+func readLine() (string, error) {
+	line, err := stdin.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return strings.TrimSpace(line), err
+}
+
+// parseJLPT returns the levels of a JLPT level like N3 or a range like
+// N5-N3 as numbers, 5 for N5 down to 1 for N1. An empty s yields nil.
+func parseJLPT(s string) ([]int, error) {
+	if s == "" {
+		return nil, nil
+	}
+
+	bounds := strings.SplitN(strings.ToUpper(s), "-", 2)
+	var levels []int
+	for _, b := range bounds {
+		n, err := strconv.Atoi(strings.TrimPrefix(strings.TrimSpace(b), "N"))
+		if err != nil || n < 1 || n > 5 {
+			return nil, fmt.Errorf("invalid JLPT level '%s', expected N5 to N1 or a range like N5-N3", s)
+		}
+		levels = append(levels, n)
+	}
+
+	from, to := levels[0], levels[len(levels)-1]
+	if from < to {
+		from, to = to, from
+	}
+	levels = nil
+	for l := from; l >= to; l-- {
+		levels = append(levels, l)
+	}
+	return levels, nil
+}
+
+// filterFailed exits with a message on an error of filtering words by
+// dict.Criteria, telling what to import if the data is missing.
+func filterFailed(err error) {
+	if err == dict.ErrNotImported {
+		log.Fatal("The dictionary lacks the data to filter by. Import JMdict again for -common and " +
+			"-exclude-archaic, and a JLPT list with 'msyu import -jlpt' for -jlpt.")
+	}
+	log.Fatal("A database error has occured:", err)
 }
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"log"
+
+	"github.com/tsurai/msyu/conjugate"
+	"github.com/tsurai/msyu/dict"
+	"github.com/tsurai/msyu/wanikani"
+)
+
+// wanikaniMatches returns the JMdict words of a WaniKani vocabulary
+// subject: the words spelled like it and read like one of its accepted
+// readings.
+func wanikaniMatches(s *wanikani.Subject) ([]*conjugate.Word, error) {
+	readings := make(map[string]bool)
+	for _, r := range s.Readings {
+		if r.AcceptedAnswer {
+			readings[r.Reading] = true
+		}
+	}
+	if len(s.Readings) == 0 {
+		// kana vocabulary is read as written
+		readings[s.Characters] = true
+	}
+
+	words, err := dict.Lookup(s.Characters, dict.ALL)
+	if err != nil {
+		return nil, err
+	}
+
+	var matches []*conjugate.Word
+	for _, w := range words {
+		if readings[w.Kana] {
+			matches = append(matches, w)
+		}
+	}
+	return matches, nil
+}
+
+// WK_store replaces the stored WaniKani vocabulary with the given words
+// by subject id.
+func WK_store(words map[int][]*conjugate.Word) {
+	tx, err := progress.Begin()
+	if err != nil {
+		log.Fatal("A database error has occured:", err)
+	}
+
+	if _, err := tx.Exec("DELETE FROM wanikani"); err != nil {
+		tx.Rollback()
+		log.Fatal("A database error has occured:", err)
+	}
+
+	for subject, ws := range words {
+		for _, w := range ws {
+			_, err := tx.Exec("INSERT OR IGNORE INTO wanikani (subject, word, kana) VALUES (?, ?, ?)", subject, w.ID, w.Kana)
+			if err != nil {
+				tx.Rollback()
+				log.Fatal("A database error has occured:", err)
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		log.Fatal("A database error has occured:", err)
+	}
+}
+
+// WK_random_words returns up to n random words of the stored WaniKani
+// vocabulary that can be conjugated and are accepted by accept.
+func WK_random_words(n int, accept func(*conjugate.Word) bool) []*conjugate.Word {
+	rows, err := progress.Query("SELECT DISTINCT word, kana FROM wanikani ORDER BY RANDOM()")
+	if err != nil {
+		log.Fatal("A database error has occured:", err)
+	}
+	defer rows.Close()
+
+	var words []*conjugate.Word
+	for len(words) < n && rows.Next() {
+		var id int
+		var kana string
+		if err := rows.Scan(&id, &kana); err != nil {
+			log.Fatal("A database error has occured:", err)
+		}
+
+		w, err := dict.GetWord(id, kana)
+		if err != nil {
+			log.Fatal("A database error has occured:", err)
+		}
+		if w != nil && w.Conjugations() != nil && accept(w) {
+			words = append(words, w)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		log.Fatal("A database error has occured:", err)
+	}
+
+	return words
+}
@@ -0,0 +1,216 @@
+// Package wanikani reads the subjects and assignments of a WaniKani user
+// from the WaniKani API v2.
+package wanikani
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultURL is the base URL of the WaniKani API v2.
+const DefaultURL = "https://api.wanikani.com/v2"
+
+// the API revision the responses are parsed as
+const revision = "20170710"
+
+// the most times a rate limited request is retried
+const maxRetries = 3
+
+// Client requests the WaniKani API with the API token of a user.
+type Client struct {
+	BaseURL string
+	Token   string
+	HTTP    *http.Client
+}
+
+// Reading is a reading of a vocabulary subject.
+type Reading struct {
+	Reading        string `json:"reading"`
+	Primary        bool   `json:"primary"`
+	AcceptedAnswer bool   `json:"accepted_answer"`
+}
+
+// Subject is a vocabulary subject. Readings is empty for kana vocabulary,
+// which is read as written.
+type Subject struct {
+	ID         int
+	Object     string
+	Characters string
+	Level      int
+	Readings   []Reading
+}
+
+// Assignment is the learning progress of a user on a subject.
+type Assignment struct {
+	SubjectID   int        `json:"subject_id"`
+	SubjectType string     `json:"subject_type"`
+	SRSStage    int        `json:"srs_stage"`
+	UnlockedAt  *time.Time `json:"unlocked_at"`
+	StartedAt   *time.Time `json:"started_at"`
+}
+
+// Error is an error response of the API.
+type Error struct {
+	Status  int    `json:"code"`
+	Message string `json:"error"`
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("wanikani: %d %s", e.Status, e.Message)
+}
+
+// collection is a page of a collection response.
+type collection struct {
+	Pages struct {
+		NextURL string `json:"next_url"`
+	} `json:"pages"`
+	Data []json.RawMessage `json:"data"`
+}
+
+// NewClient returns a client for the API at baseURL, DefaultURL if empty.
+func NewClient(baseURL string, token string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultURL
+	}
+	return &Client{BaseURL: strings.TrimRight(baseURL, "/"), Token: token, HTTP: &http.Client{Timeout: time.Minute}}
+}
+
+// Vocabulary returns every vocabulary and kana vocabulary subject.
+func (c *Client) Vocabulary() ([]*Subject, error) {
+	var subjects []*Subject
+
+	err := c.collect("/subjects?types=vocabulary,kana_vocabulary", func(raw json.RawMessage) error {
+		var r struct {
+			ID     int    `json:"id"`
+			Object string `json:"object"`
+			Data   struct {
+				Characters string    `json:"characters"`
+				Level      int       `json:"level"`
+				Readings   []Reading `json:"readings"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(raw, &r); err != nil {
+			return err
+		}
+
+		subjects = append(subjects, &Subject{ID: r.ID, Object: r.Object, Characters: r.Data.Characters,
+			Level: r.Data.Level, Readings: r.Data.Readings})
+		return nil
+	})
+
+	return subjects, err
+}
+
+// UnlockedVocabulary returns the assignments of the vocabulary subjects
+// the user has unlocked.
+func (c *Client) UnlockedVocabulary() ([]*Assignment, error) {
+	var assignments []*Assignment
+
+	err := c.collect("/assignments?subject_types=vocabulary,kana_vocabulary&unlocked=true", func(raw json.RawMessage) error {
+		var r struct {
+			Data Assignment `json:"data"`
+		}
+		if err := json.Unmarshal(raw, &r); err != nil {
+			return err
+		}
+
+		assignments = append(assignments, &r.Data)
+		return nil
+	})
+
+	return assignments, err
+}
+
+// collect calls item with every element of the collection at path,
+// following its pages.
+func (c *Client) collect(path string, item func(json.RawMessage) error) error {
+	next := c.BaseURL + path
+	for next != "" {
+		var page collection
+		if err := c.get(next, &page); err != nil {
+			return err
+		}
+
+		for _, raw := range page.Data {
+			if err := item(raw); err != nil {
+				return err
+			}
+		}
+
+		// the next page is given as absolute URL of the real API, so only
+		// its query is kept to stay on BaseURL
+		next = ""
+		if page.Pages.NextURL != "" {
+			u, err := url.Parse(page.Pages.NextURL)
+			if err != nil {
+				return err
+			}
+			next = c.BaseURL + strings.TrimPrefix(u.Path, basePath(c.BaseURL)) + "?" + u.RawQuery
+		}
+	}
+	return nil
+}
+
+// basePath returns the path of the base URL, e.g. /v2.
+func basePath(baseURL string) string {
+	if u, err := url.Parse(baseURL); err == nil {
+		return u.Path
+	}
+	return ""
+}
+
+// get requests u and decodes the JSON response into v. Rate limited
+// requests are retried once the limit resets.
+func (c *Client) get(u string, v interface{}) error {
+	for try := 0; ; try++ {
+		req, err := http.NewRequest(http.MethodGet, u, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Authorization", "Bearer "+c.Token)
+		req.Header.Set("Wanikani-Revision", revision)
+
+		res, err := c.HTTP.Do(req)
+		if err != nil {
+			return err
+		}
+
+		if res.StatusCode == http.StatusTooManyRequests && try < maxRetries {
+			res.Body.Close()
+			time.Sleep(retryDelay(res.Header.Get("RateLimit-Reset")))
+			continue
+		}
+
+		defer res.Body.Close()
+		if res.StatusCode != http.StatusOK {
+			e := &Error{}
+			if json.NewDecoder(res.Body).Decode(e) != nil || e.Message == "" {
+				e.Message = http.StatusText(res.StatusCode)
+			}
+			e.Status = res.StatusCode
+			return e
+		}
+
+		return json.NewDecoder(res.Body).Decode(v)
+	}
+}
+
+// retryDelay returns how long to wait for the rate limit to reset at the
+// given unix time, at least a second and at most a minute.
+func retryDelay(reset string) time.Duration {
+	d := time.Second
+	if t, err := strconv.ParseInt(reset, 10, 64); err == nil {
+		if until := time.Until(time.Unix(t, 0)); until > d {
+			d = until
+		}
+	}
+	if d > time.Minute {
+		d = time.Minute
+	}
+	return d
+}